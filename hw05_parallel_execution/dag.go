@@ -0,0 +1,320 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicateNode     = errors.New("duplicate node")
+	ErrUnknownDependency = errors.New("unknown dependency")
+	ErrDependencyFailed  = errors.New("dependency failed")
+	ErrNodesFailed       = errors.New("nodes failed")
+)
+
+// NodeStatus is the state of a graph node after the run.
+type NodeStatus int
+
+const (
+	NodePending NodeStatus = iota
+	NodeSucceeded
+	NodeFailed
+	NodeSkipped
+)
+
+func (s NodeStatus) String() string {
+	switch s {
+	case NodePending:
+		return "pending"
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("NodeStatus(%d)", int(s))
+	}
+}
+
+// CycleError is returned by Graph.Run when dependencies contain a cycle.
+type CycleError struct {
+	// Path lists node IDs of the cycle, the first one is repeated at the end.
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// NodeResult describes the outcome of a single node.
+type NodeResult struct {
+	Status NodeStatus
+	// Err is the task error for failed nodes and wraps ErrDependencyFailed for skipped ones.
+	Err      error
+	Attempts int
+	Duration time.Duration
+}
+
+// GraphResult contains results of all nodes by their IDs.
+type GraphResult struct {
+	Nodes map[string]NodeResult
+	// ids keeps the order in which nodes were added to the graph
+	ids []string
+}
+
+// Failed returns IDs of failed nodes in the order they were added.
+func (r *GraphResult) Failed() []string {
+	return r.withStatus(NodeFailed)
+}
+
+// Skipped returns IDs of nodes skipped because of failed dependencies in the order they were added.
+func (r *GraphResult) Skipped() []string {
+	return r.withStatus(NodeSkipped)
+}
+
+func (r *GraphResult) withStatus(status NodeStatus) []string {
+	ids := make([]string, 0)
+	for _, id := range r.ids {
+		if r.Nodes[id].Status == status {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+type graphNode struct {
+	id   string
+	task Task
+	deps []string
+}
+
+// Graph is a set of tasks with dependencies between them.
+type Graph struct {
+	nodes map[string]*graphNode
+	// ids keeps the order in which nodes were added
+	ids []string
+}
+
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[string]*graphNode)}
+}
+
+// Add adds the task with the given ID which is started after all deps succeed.
+// Dependencies may be added later, they are checked by Run.
+func (g *Graph) Add(id string, task Task, deps ...string) error {
+	if _, ok := g.nodes[id]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateNode, id)
+	}
+
+	g.nodes[id] = &graphNode{id: id, task: task, deps: deps}
+	g.ids = append(g.ids, id)
+
+	return nil
+}
+
+// Run executes tasks in n goroutines, a task is started once all its dependencies succeed.
+// Dependents of a failed task are skipped. The graph is validated before any task is started,
+// a cycle is reported as *CycleError. If some nodes failed, the error wraps ErrNodesFailed
+// and the result contains the status of every node.
+// WithRetry and WithClock options are applied to every task, panics are recovered as *PanicError.
+func (g *Graph) Run(n int, opts ...Option) (*GraphResult, error) {
+	if n <= 0 {
+		return nil, ErrInvalidWorkersCount
+	}
+
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	s := newGraphScheduler(g)
+
+	type completion struct {
+		id     string
+		result NodeResult
+	}
+
+	workChan := make(chan *graphNode)
+	completionChan := make(chan completion)
+	wg := &sync.WaitGroup{}
+
+	workersCount := min(n, len(g.nodes))
+	wg.Add(workersCount)
+	for range workersCount {
+		go func() {
+			defer wg.Done()
+
+			for node := range workChan {
+				start := o.clock.Now()
+				attempts, err := o.runTask(node.task, nil)
+				res := NodeResult{
+					Status:   NodeSucceeded,
+					Err:      err,
+					Attempts: attempts,
+					Duration: o.clock.Now().Sub(start),
+				}
+				if err != nil {
+					res.Status = NodeFailed
+				}
+				completionChan <- completion{id: node.id, result: res}
+			}
+		}()
+	}
+
+	running := 0
+	for running > 0 || len(s.ready) > 0 {
+		var (
+			readyChan chan *graphNode
+			next      *graphNode
+		)
+		if len(s.ready) > 0 {
+			readyChan, next = workChan, g.nodes[s.ready[0]]
+		}
+
+		select {
+		case readyChan <- next:
+			s.ready = s.ready[1:]
+			running++
+		case c := <-completionChan:
+			running--
+			s.complete(c.id, c.result)
+		}
+	}
+
+	close(workChan)
+	wg.Wait()
+
+	result := &GraphResult{Nodes: s.results, ids: g.ids}
+	if failed := result.Failed(); len(failed) > 0 {
+		return result, fmt.Errorf("%w: %s", ErrNodesFailed, strings.Join(failed, ", "))
+	}
+
+	return result, nil
+}
+
+// validate checks that all dependencies exist and there are no cycles.
+func (g *Graph) validate() error {
+	for _, id := range g.ids {
+		for _, dep := range g.nodes[id].deps {
+			if _, ok := g.nodes[dep]; !ok {
+				return fmt.Errorf("%w: %q required by %q", ErrUnknownDependency, dep, id)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		inProgress
+		visited
+	)
+	state := make(map[string]int, len(g.nodes))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case inProgress:
+			start := 0
+			for path[start] != id {
+				start++
+			}
+			cycle := append(append([]string(nil), path[start:]...), id)
+			return &CycleError{Path: cycle}
+		}
+
+		state[id] = inProgress
+		path = append(path, id)
+		for _, dep := range g.nodes[id].deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+
+		return nil
+	}
+
+	for _, id := range g.ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// graphScheduler tracks which nodes are ready to start. It is used by a single goroutine.
+type graphScheduler struct {
+	dependents map[string][]string
+	// remaining is the number of dependencies that have not succeeded yet
+	remaining map[string]int
+	ready     []string
+	results   map[string]NodeResult
+}
+
+func newGraphScheduler(g *Graph) *graphScheduler {
+	s := &graphScheduler{
+		dependents: make(map[string][]string, len(g.nodes)),
+		remaining:  make(map[string]int, len(g.nodes)),
+		results:    make(map[string]NodeResult, len(g.nodes)),
+	}
+
+	for _, id := range g.ids {
+		node := g.nodes[id]
+		s.results[id] = NodeResult{Status: NodePending}
+		s.remaining[id] = len(node.deps)
+		if len(node.deps) == 0 {
+			s.ready = append(s.ready, id)
+		}
+		for _, dep := range node.deps {
+			s.dependents[dep] = append(s.dependents[dep], id)
+		}
+	}
+
+	return s
+}
+
+func (s *graphScheduler) complete(id string, res NodeResult) {
+	s.results[id] = res
+
+	if res.Status == NodeFailed {
+		s.skipDependents(id)
+		return
+	}
+
+	for _, dependent := range s.dependents[id] {
+		s.remaining[dependent]--
+		if s.remaining[dependent] == 0 && s.results[dependent].Status == NodePending {
+			s.ready = append(s.ready, dependent)
+		}
+	}
+}
+
+// skipDependents marks all direct and transitive dependents of the failed node as skipped.
+func (s *graphScheduler) skipDependents(failedID string) {
+	queue := []string{failedID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range s.dependents[id] {
+			if s.results[dependent].Status != NodePending {
+				continue
+			}
+
+			s.results[dependent] = NodeResult{
+				Status: NodeSkipped,
+				Err:    fmt.Errorf("%w: %q", ErrDependencyFailed, failedID),
+			}
+			queue = append(queue, dependent)
+		}
+	}
+}
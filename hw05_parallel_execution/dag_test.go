@@ -0,0 +1,108 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestGraph(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("dependencies are finished before dependents start", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			finished = make(map[string]bool)
+		)
+		deps := map[string][]string{
+			"fetch":   nil,
+			"config":  nil,
+			"compile": {"fetch", "config"},
+			"test":    {"compile"},
+			"lint":    {"fetch"},
+			"package": {"test", "lint"},
+		}
+
+		g := NewGraph()
+		for _, id := range []string{"fetch", "config", "compile", "test", "lint", "package"} {
+			err := g.Add(id, func() error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				for _, dep := range deps[id] {
+					if !finished[dep] {
+						return errors.New(dep + " is not finished before " + id)
+					}
+				}
+				finished[id] = true
+				return nil
+			}, deps[id]...)
+			require.NoError(t, err)
+		}
+
+		result, err := g.Run(3)
+		require.NoError(t, err)
+		require.Len(t, result.Nodes, 6)
+		for id, res := range result.Nodes {
+			require.Equal(t, NodeSucceeded, res.Status, id)
+		}
+	})
+
+	t.Run("dependents of failed node are skipped", func(t *testing.T) {
+		errBuild := errors.New("build failed")
+		ok := func() error { return nil }
+
+		g := NewGraph()
+		require.NoError(t, g.Add("build", func() error { return errBuild }))
+		require.NoError(t, g.Add("docs", ok))
+		require.NoError(t, g.Add("test", ok, "build"))
+		require.NoError(t, g.Add("deploy", ok, "test", "docs"))
+
+		result, err := g.Run(2)
+		require.ErrorIs(t, err, ErrNodesFailed)
+		require.Equal(t, []string{"build"}, result.Failed())
+		require.Equal(t, []string{"test", "deploy"}, result.Skipped())
+		require.ErrorIs(t, result.Nodes["build"].Err, errBuild)
+		require.ErrorIs(t, result.Nodes["deploy"].Err, ErrDependencyFailed)
+		require.Equal(t, NodeSucceeded, result.Nodes["docs"].Status)
+	})
+
+	t.Run("cycle is detected before run", func(t *testing.T) {
+		var started bool
+		task := func() error {
+			started = true
+			return nil
+		}
+
+		g := NewGraph()
+		require.NoError(t, g.Add("a", task))
+		require.NoError(t, g.Add("b", task, "a", "d"))
+		require.NoError(t, g.Add("c", task, "b"))
+		require.NoError(t, g.Add("d", task, "c"))
+
+		_, err := g.Run(2)
+		var cycleErr *CycleError
+		require.ErrorAs(t, err, &cycleErr)
+		require.Equal(t, []string{"b", "d", "c", "b"}, cycleErr.Path)
+		require.EqualError(t, err, "dependency cycle: b -> d -> c -> b")
+		require.False(t, started)
+	})
+
+	t.Run("invalid graph", func(t *testing.T) {
+		task := func() error { return nil }
+
+		g := NewGraph()
+		require.NoError(t, g.Add("a", task))
+		require.ErrorIs(t, g.Add("a", task), ErrDuplicateNode)
+
+		require.NoError(t, g.Add("b", task, "unknown"))
+		_, err := g.Run(1)
+		require.ErrorIs(t, err, ErrUnknownDependency)
+
+		_, err = NewGraph().Run(0)
+		require.ErrorIs(t, err, ErrInvalidWorkersCount)
+	})
+}
@@ -0,0 +1,71 @@
+package hw05parallelexecution
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TaskError describes a failure of a single task.
+type TaskError struct {
+	// Index is the position of the task in the slice passed to Run.
+	Index int
+	Err   error
+	// Attempts is the number of times the task was run, greater than 1 with retries.
+	Attempts int
+	// Duration is the time spent on all attempts including backoff delays.
+	Duration time.Duration
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned by Run when the errors limit is exceeded or a task panicked with PanicAbort policy.
+// It matches Reason and every task error with errors.Is, and *TaskError with errors.As.
+type RunError struct {
+	// Reason is ErrErrorsLimitExceeded or ErrTaskPanicked.
+	Reason error
+	// Errors are the failures of completed tasks ordered by task index.
+	Errors []*TaskError
+	// Succeeded is the number of tasks completed without error.
+	Succeeded int
+}
+
+func newRunError(reason error, failures []*TaskError, succeeded int) *RunError {
+	errs := slices.Clone(failures)
+	slices.SortFunc(errs, func(a, b *TaskError) int {
+		return a.Index - b.Index
+	})
+
+	return &RunError{Reason: reason, Errors: errs, Succeeded: succeeded}
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%v: %d of %d completed tasks failed",
+		e.Reason, len(e.Errors), len(e.Errors)+e.Succeeded)
+}
+
+func (e *RunError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors)+1)
+	errs = append(errs, e.Reason)
+	for _, taskErr := range e.Errors {
+		errs = append(errs, taskErr)
+	}
+
+	return errs
+}
+
+// Failed returns indexes of failed tasks, e.g. to retry them.
+func (e *RunError) Failed() []int {
+	indexes := make([]int, 0, len(e.Errors))
+	for _, taskErr := range e.Errors {
+		indexes = append(indexes, taskErr.Index)
+	}
+
+	return indexes
+}
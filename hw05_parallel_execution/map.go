@@ -0,0 +1,176 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of fn for a single input of MapStream.
+type Result[T any] struct {
+	// Index is the position of the input in the stream.
+	Index int
+	Value T
+	Err   error
+}
+
+// Map applies fn to inputs in WithWorkers goroutines and returns results in input order.
+// It stops dispatching inputs when WithErrorsLimit errors are received or ctx is done.
+// On error the results of inputs that were not processed successfully are zero values.
+func Map[In, Out any](
+	ctx context.Context,
+	inputs []In,
+	fn func(ctx context.Context, in In) (Out, error),
+	opts ...Option,
+) ([]Out, error) {
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]Out, len(inputs))
+	taskChan := make(chan indexedTask)
+	e := newExecutor(o)
+	stopAbort := context.AfterFunc(ctx, e.abort)
+	defer stopAbort()
+
+	wait := e.startWorkers(min(o.workers, len(inputs)), taskChan, nil)
+
+	limiter := o.newLimiter()
+
+dispatch:
+	for _, i := range o.dispatchOrder(len(inputs)) {
+		if limiter != nil && !limiter.wait(e.stop) {
+			break
+		}
+
+		in := inputs[i]
+		task := func() error {
+			out, err := fn(ctx, in)
+			if err == nil {
+				results[i] = out
+			}
+			return err
+		}
+
+		select {
+		case taskChan <- indexedTask{index: i, task: task}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
+		if e.stopped() {
+			break
+		}
+	}
+
+	close(taskChan)
+	wait()
+
+	if err := e.err(); err != nil {
+		return results, err
+	}
+
+	if ctx.Err() != nil {
+		return results, context.Cause(ctx)
+	}
+
+	return results, nil
+}
+
+// MapStream applies fn to values received from inputs in WithWorkers goroutines
+// and emits results as they finish, so the order is not preserved.
+// It stops reading inputs when the channel is closed, WithErrorsLimit errors are received or ctx is done.
+// The results channel must be read until it is closed, then wait returns the error of the whole run.
+func MapStream[In, Out any](
+	ctx context.Context,
+	inputs <-chan In,
+	fn func(ctx context.Context, in In) (Out, error),
+	opts ...Option,
+) (results <-chan Result[Out], wait func() error) {
+	out := make(chan Result[Out])
+
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		close(out)
+		return out, func() error { return err }
+	}
+
+	taskChan := make(chan indexedTask)
+	e := newExecutor(o)
+	stopAbort := context.AfterFunc(ctx, e.abort)
+
+	// values are stored by index while the task runs, so the report can emit them
+	var values sync.Map
+	waitWorkers := e.startWorkers(o.workers, taskChan, func(index int, err error) {
+		r := Result[Out]{Index: index, Err: err}
+		if v, ok := values.LoadAndDelete(index); ok {
+			r.Value = v.(Out)
+		}
+
+		select {
+		case out <- r:
+		case <-ctx.Done():
+		}
+	})
+
+	limiter := o.newLimiter()
+
+	go func() {
+		defer func() {
+			close(taskChan)
+			waitWorkers()
+			stopAbort()
+			close(out)
+		}()
+
+		for i := 0; ; i++ {
+			var (
+				in In
+				ok bool
+			)
+			select {
+			case in, ok = <-inputs:
+				if !ok {
+					return
+				}
+			case <-e.stop:
+				return
+			}
+
+			if limiter != nil && !limiter.wait(e.stop) {
+				return
+			}
+
+			task := func() error {
+				v, err := fn(ctx, in)
+				if err == nil {
+					values.Store(i, v)
+				}
+				return err
+			}
+
+			select {
+			case taskChan <- indexedTask{index: i, task: task}:
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+
+	var err error
+	var once sync.Once
+
+	return out, func() error {
+		once.Do(func() {
+			//nolint:revive
+			for range out {
+			}
+
+			err = e.err()
+			if err == nil && ctx.Err() != nil {
+				err = context.Cause(ctx)
+			}
+		})
+		return err
+	}
+}
@@ -0,0 +1,150 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMap(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	itoa := func(_ context.Context, v int) (string, error) {
+		time.Sleep(time.Millisecond * time.Duration(rand.Intn(10)))
+		return strconv.Itoa(v), nil
+	}
+
+	t.Run("results are in input order", func(t *testing.T) {
+		inputs := make([]int, 100)
+		expected := make([]string, 100)
+		for i := range inputs {
+			inputs[i] = i
+			expected[i] = strconv.Itoa(i)
+		}
+
+		results, err := Map(context.Background(), inputs, itoa, WithWorkers(10))
+		require.NoError(t, err)
+		require.Equal(t, expected, results)
+	})
+
+	t.Run("errors limit exceeded", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		var calls atomic.Int32
+		inputs := make([]int, 50)
+		for i := range inputs {
+			inputs[i] = i
+		}
+
+		workersCount, maxErrorsCount := 5, 3
+		results, err := Map(context.Background(), inputs, func(_ context.Context, _ int) (int, error) {
+			calls.Add(1)
+			return 0, errFailed
+		}, WithWorkers(workersCount), WithErrorsLimit(maxErrorsCount))
+
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.ErrorIs(t, err, errFailed)
+		require.Len(t, runErr.Errors, int(calls.Load()))
+		require.LessOrEqual(t, calls.Load(), int32(workersCount+maxErrorsCount), "extra tasks were started")
+		require.Len(t, results, len(inputs))
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Map(ctx, []int{1, 2, 3}, itoa, WithWorkers(2))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("invalid workers count", func(t *testing.T) {
+		_, err := Map(context.Background(), []int{1}, itoa, WithWorkers(0))
+		require.ErrorIs(t, err, ErrInvalidWorkersCount)
+	})
+}
+
+func TestMapStream(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("all inputs are processed", func(t *testing.T) {
+		inputs := make(chan int)
+		go func() {
+			defer close(inputs)
+			for i := range 20 {
+				inputs <- i
+			}
+		}()
+
+		results, wait := MapStream(context.Background(), inputs, func(_ context.Context, v int) (int, error) {
+			time.Sleep(time.Millisecond * time.Duration(rand.Intn(10)))
+			return v * v, nil
+		}, WithWorkers(4))
+
+		indexes := make([]int, 0, 20)
+		for r := range results {
+			require.NoError(t, r.Err)
+			require.Equal(t, r.Index*r.Index, r.Value)
+			indexes = append(indexes, r.Index)
+		}
+		require.NoError(t, wait())
+
+		sort.Ints(indexes)
+		for i, index := range indexes {
+			require.Equal(t, i, index)
+		}
+		require.Len(t, indexes, 20)
+	})
+
+	t.Run("errors limit stops reading inputs", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		inputs := make(chan int)
+		produced := make(chan struct{})
+		go func() {
+			defer close(produced)
+			for i := range 100 {
+				select {
+				case inputs <- i:
+				case <-time.After(100 * time.Millisecond):
+					return
+				}
+			}
+		}()
+
+		results, wait := MapStream(context.Background(), inputs, func(_ context.Context, _ int) (int, error) {
+			return 0, errFailed
+		}, WithWorkers(2), WithErrorsLimit(2))
+
+		var failed int
+		for r := range results {
+			require.ErrorIs(t, r.Err, errFailed)
+			failed++
+		}
+		require.LessOrEqual(t, failed, 4)
+
+		err := wait()
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, errFailed)
+		<-produced
+	})
+
+	t.Run("wait without reading results", func(t *testing.T) {
+		inputs := make(chan int, 3)
+		inputs <- 1
+		inputs <- 2
+		inputs <- 3
+		close(inputs)
+
+		_, wait := MapStream(context.Background(), inputs, func(_ context.Context, v int) (int, error) {
+			return v, nil
+		})
+		require.NoError(t, wait())
+	})
+}
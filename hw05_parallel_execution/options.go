@@ -0,0 +1,164 @@
+package hw05parallelexecution
+
+import (
+	"cmp"
+	"errors"
+	"runtime"
+	"slices"
+	"time"
+)
+
+var ErrInvalidRateLimit = errors.New("invalid rate limit")
+
+// Option configures task execution.
+type Option func(*options)
+
+type options struct {
+	workers     int
+	errorPolicy *ErrorPolicy
+	retry       *RetryPolicy
+	clock       Clock
+	rateLimit   float64
+	burst       int
+	priority    func(index int) int
+	panicPolicy PanicPolicy
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		workers: runtime.GOMAXPROCS(0),
+		clock:   realClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// policy returns the error policy, FailFast by default.
+func (o *options) policy() ErrorPolicy {
+	if o.errorPolicy == nil {
+		return FailFast()
+	}
+
+	return *o.errorPolicy
+}
+
+func (o *options) validate() error {
+	if err := o.policy().validate(); err != nil {
+		return err
+	}
+
+	if o.workers <= 0 {
+		return ErrInvalidWorkersCount
+	}
+
+	if o.rateLimit < 0 {
+		return ErrInvalidRateLimit
+	}
+
+	return nil
+}
+
+// newLimiter returns nil if the rate is not limited.
+func (o *options) newLimiter() *tokenBucket {
+	if o.rateLimit == 0 {
+		return nil
+	}
+
+	return newTokenBucket(o.clock, o.rateLimit, o.burst)
+}
+
+// dispatchOrder returns indexes of n tasks in the order they must be dispatched:
+// by descending priority, tasks with equal priority keep their order.
+func (o *options) dispatchOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	if o.priority != nil {
+		priorities := make([]int, n)
+		for i := range priorities {
+			priorities[i] = o.priority(i)
+		}
+
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(priorities[b], priorities[a])
+		})
+	}
+
+	return order
+}
+
+// runTask runs the task once or, if the retry policy is set, until it is satisfied.
+// Panics are recovered and returned as *PanicError.
+func (o *options) runTask(task Task, stop <-chan struct{}) (attempts int, err error) {
+	if o.retry == nil {
+		return 1, recoverTask(task)
+	}
+
+	return o.retry.do(task, o.clock, stop)
+}
+
+// WithWorkers sets the number of workers for Map, GOMAXPROCS by default.
+// Run takes it as an argument.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// WithErrorsLimit sets the number of errors that stops Map, 1 by default.
+// It is a shortcut for WithErrorPolicy(MaxErrors(m)), Run takes it as an argument.
+func WithErrorsLimit(m int) Option {
+	return WithErrorPolicy(MaxErrors(m))
+}
+
+// WithRetry makes workers retry failed tasks according to the policy
+// before the error is counted toward the errors limit.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+// WithRateLimit limits the rate of starting tasks to perSecond with bursts of up to burst tasks.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = perSecond
+		o.burst = burst
+	}
+}
+
+// WithPriority makes Run and Map dispatch tasks with higher priority first,
+// priority is called once per task with its index.
+func WithPriority(priority func(index int) int) Option {
+	return func(o *options) {
+		o.priority = priority
+	}
+}
+
+// WithClock replaces the system clock used for backoff delays and rate limiting.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// Clock is a source of time, it allows to control delays in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,58 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+var ErrTaskPanicked = errors.New("task panicked")
+
+// PanicPolicy defines what happens with the run when a task panics.
+type PanicPolicy int
+
+const (
+	// PanicContinue counts the panic toward the errors limit like any other error.
+	PanicContinue PanicPolicy = iota
+	// PanicAbort stops starting new tasks after the first panic.
+	PanicAbort
+)
+
+// PanicError is the error of a task recovered from panic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panicked goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrTaskPanicked, e.Value)
+}
+
+// Unwrap allows to match ErrTaskPanicked and the error passed to panic.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrTaskPanicked, err}
+	}
+
+	return []error{ErrTaskPanicked}
+}
+
+// WithPanicPolicy sets the reaction of Run and Map on panics in tasks, PanicContinue by default.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(o *options) {
+		o.panicPolicy = policy
+	}
+}
+
+// recoverTask converts a panic in the task into *PanicError.
+func recoverTask(task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return task()
+}
@@ -0,0 +1,97 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunPanic(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("panic is counted as error", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		tasks := make([]Task, 10)
+		for i := range tasks {
+			tasks[i] = func() error {
+				runTasksCount.Add(1)
+				if i == 3 {
+					panic("boom")
+				}
+				return nil
+			}
+		}
+
+		err := Run(tasks, 2, 2)
+		require.NoError(t, err)
+		require.Equal(t, int32(10), runTasksCount.Load())
+
+		err = Run(tasks[3:4], 1, 1)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, ErrTaskPanicked)
+
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		require.Equal(t, "boom", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "panic_test.go")
+	})
+
+	t.Run("panic with error value", func(t *testing.T) {
+		errPanic := errors.New("panic error")
+		err := Run([]Task{func() error { panic(errPanic) }}, 1, 1)
+		require.ErrorIs(t, err, errPanic)
+	})
+
+	t.Run("abort on panic", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		tasks := make([]Task, 50)
+		for i := range tasks {
+			tasks[i] = func() error {
+				runTasksCount.Add(1)
+				if i == 0 {
+					panic("boom")
+				}
+				time.Sleep(time.Millisecond)
+				return nil
+			}
+		}
+
+		workersCount := 5
+		err := Run(tasks, workersCount, 10, WithPanicPolicy(PanicAbort))
+
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.ErrorIs(t, err, ErrTaskPanicked)
+		require.NotErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.Equal(t, []int{0}, runErr.Failed())
+		require.LessOrEqual(t, runTasksCount.Load(), int32(workersCount+1), "extra tasks were started")
+	})
+
+	t.Run("panic is not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		err := Run([]Task{func() error {
+			calls.Add(1)
+			panic("boom")
+		}}, 1, 1, WithClock(newFakeClock()), WithRetry(RetryPolicy{MaxAttempts: 3}))
+		require.ErrorIs(t, err, ErrTaskPanicked)
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("pool survives panic", func(t *testing.T) {
+		pool, err := NewPool(1, 1)
+		require.NoError(t, err)
+
+		require.NoError(t, pool.Submit(context.Background(), func() error { panic("boom") }))
+		require.NoError(t, pool.Submit(context.Background(), func() error { return nil }))
+		require.NoError(t, pool.Shutdown(context.Background()))
+
+		stats := pool.Stats()
+		require.Equal(t, int64(2), stats.Completed)
+		require.Equal(t, int64(1), stats.Failed)
+	})
+}
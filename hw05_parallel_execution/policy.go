@@ -0,0 +1,82 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidErrorPolicy = errors.New("invalid error policy")
+
+type errorPolicyKind int
+
+const (
+	maxErrorsPolicy errorPolicyKind = iota
+	ignoreErrorsPolicy
+	errorRatioPolicy
+)
+
+// ErrorPolicy decides when task errors stop the run with ErrErrorsLimitExceeded.
+type ErrorPolicy struct {
+	kind      errorPolicyKind
+	limit     int
+	ratio     float64
+	minSample int
+}
+
+// MaxErrors stops the run when m tasks fail, m <= 0 means the run fails immediately.
+func MaxErrors(m int) ErrorPolicy {
+	return ErrorPolicy{kind: maxErrorsPolicy, limit: m}
+}
+
+// FailFast stops the run on the first error.
+func FailFast() ErrorPolicy {
+	return MaxErrors(1)
+}
+
+// IgnoreErrors never stops the run, all tasks are executed.
+func IgnoreErrors() ErrorPolicy {
+	return ErrorPolicy{kind: ignoreErrorsPolicy}
+}
+
+// ErrorRatio stops the run when more than ratio (from 0 to 1) of completed tasks failed.
+// The ratio is checked only after minSample tasks are completed.
+func ErrorRatio(ratio float64, minSample int) ErrorPolicy {
+	return ErrorPolicy{kind: errorRatioPolicy, ratio: ratio, minSample: max(minSample, 1)}
+}
+
+func (p ErrorPolicy) validate() error {
+	switch p.kind {
+	case maxErrorsPolicy:
+		if p.limit <= 0 {
+			return ErrErrorsLimitExceeded
+		}
+	case errorRatioPolicy:
+		if p.ratio < 0 || p.ratio > 1 {
+			return fmt.Errorf("%w: ratio %v is out of [0, 1]", ErrInvalidErrorPolicy, p.ratio)
+		}
+	case ignoreErrorsPolicy:
+	}
+
+	return nil
+}
+
+// exceeded reports whether the run must be stopped after completed tasks of which failed ones.
+func (p ErrorPolicy) exceeded(failed, completed int) bool {
+	switch p.kind {
+	case maxErrorsPolicy:
+		return failed >= p.limit
+	case errorRatioPolicy:
+		return completed >= p.minSample && float64(failed) > p.ratio*float64(completed)
+	case ignoreErrorsPolicy:
+	}
+
+	return false
+}
+
+// WithErrorPolicy sets the policy stopping Run and Map on task errors.
+// For Run it replaces the limit passed as m.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(o *options) {
+		o.errorPolicy = &policy
+	}
+}
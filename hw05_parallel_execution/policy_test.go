@@ -0,0 +1,94 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestErrorPolicy(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	errTask := errors.New("task error")
+	// every task with index divisible by failEvery fails
+	makeTasks := func(count, failEvery int, runTasksCount *atomic.Int32) []Task {
+		tasks := make([]Task, count)
+		for i := range tasks {
+			tasks[i] = func() error {
+				runTasksCount.Add(1)
+				if i%failEvery == 0 {
+					return errTask
+				}
+				return nil
+			}
+		}
+		return tasks
+	}
+
+	t.Run("ignore errors", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		tasks := makeTasks(50, 1, &runTasksCount)
+
+		err := Run(tasks, 5, 0, WithErrorPolicy(IgnoreErrors()))
+		require.NoError(t, err)
+		require.Equal(t, int32(50), runTasksCount.Load())
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		tasks := makeTasks(50, 1, &runTasksCount)
+
+		workersCount := 5
+		err := Run(tasks, workersCount, 100, WithErrorPolicy(FailFast()))
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.LessOrEqual(t, runTasksCount.Load(), int32(workersCount+1), "extra tasks were started")
+	})
+
+	t.Run("ratio below threshold", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		// 10% of tasks fail
+		tasks := makeTasks(100, 10, &runTasksCount)
+
+		err := Run(tasks, 1, 0, WithErrorPolicy(ErrorRatio(0.2, 10)))
+		require.NoError(t, err)
+		require.Equal(t, int32(100), runTasksCount.Load())
+	})
+
+	t.Run("ratio above threshold", func(t *testing.T) {
+		var runTasksCount atomic.Int32
+		// 50% of tasks fail
+		tasks := makeTasks(100, 2, &runTasksCount)
+
+		err := Run(tasks, 1, 0, WithErrorPolicy(ErrorRatio(0.2, 10)))
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		// the ratio is checked after 10 completed tasks, one more may be already dispatched
+		require.LessOrEqual(t, runTasksCount.Load(), int32(11))
+		require.GreaterOrEqual(t, runTasksCount.Load(), int32(10))
+	})
+
+	t.Run("policy for map", func(t *testing.T) {
+		inputs := []int{1, 2, 3, 4}
+		results, err := Map(context.Background(), inputs, func(_ context.Context, v int) (int, error) {
+			if v%2 == 0 {
+				return 0, errTask
+			}
+			return v, nil
+		}, WithWorkers(2), WithErrorPolicy(IgnoreErrors()))
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 0, 3, 0}, results)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		err := Run(nil, 1, 1, WithErrorPolicy(ErrorRatio(1.5, 1)))
+		require.ErrorIs(t, err, ErrInvalidErrorPolicy)
+
+		err = Run(nil, 1, 1, WithErrorPolicy(MaxErrors(0)))
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+	})
+}
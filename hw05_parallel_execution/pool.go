@@ -0,0 +1,312 @@
+package hw05parallelexecution
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrPoolClosed       = errors.New("pool is closed")
+	ErrInvalidQueueSize = errors.New("invalid queue size")
+)
+
+// Pool is a long-lived set of workers executing submitted tasks.
+// Tasks wait for a free worker in a bounded queue, Submit blocks when the queue is full.
+// Queued tasks with higher priority are started first. Panics in tasks are recovered and counted as failures.
+type Pool struct {
+	o         *options
+	queueSize int
+
+	submitChan chan queuedTask
+	workChan   chan Task
+	// shutdownChan is closed to stop accepting tasks and drain the queue
+	shutdownChan chan struct{}
+	// abandonChan is closed to drop queued tasks and interrupt retries
+	abandonChan chan struct{}
+	abandonOnce sync.Once
+
+	mu     sync.Mutex
+	closed bool
+	// stops contains a stop channel for every running worker
+	stops []chan struct{}
+	wg    sync.WaitGroup
+
+	queueDepth atomic.Int64
+	active     atomic.Int64
+	completed  atomic.Int64
+	failed     atomic.Int64
+	abandoned  atomic.Int64
+}
+
+// PoolStats is a snapshot of the pool state.
+type PoolStats struct {
+	Workers       int
+	ActiveWorkers int
+	QueueDepth    int
+	Completed     int64
+	Failed        int64
+	Abandoned     int64
+}
+
+// NewPool starts n workers and a queue for queueSize tasks waiting for a worker.
+// WithRetry, WithRateLimit and WithClock options are applied to every task.
+func NewPool(n, queueSize int, opts ...Option) (*Pool, error) {
+	if n <= 0 {
+		return nil, ErrInvalidWorkersCount
+	}
+
+	if queueSize <= 0 {
+		return nil, ErrInvalidQueueSize
+	}
+
+	p := &Pool{
+		o:            newOptions(opts),
+		queueSize:    queueSize,
+		submitChan:   make(chan queuedTask),
+		workChan:     make(chan Task),
+		shutdownChan: make(chan struct{}),
+		abandonChan:  make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.dispatch()
+
+	p.mu.Lock()
+	p.addWorkers(n)
+	p.mu.Unlock()
+
+	return p, nil
+}
+
+// Submit puts the task to the queue, waiting for a free place until ctx is done.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	return p.SubmitPriority(ctx, task, 0)
+}
+
+// SubmitPriority puts the task with the given priority to the queue,
+// it is started before queued tasks with lower priority.
+func (p *Pool) SubmitPriority(ctx context.Context, task Task, priority int) error {
+	select {
+	case <-p.shutdownChan:
+		return ErrPoolClosed
+	default:
+	}
+
+	select {
+	case p.submitChan <- queuedTask{task: task, priority: priority}:
+		return nil
+	case <-p.shutdownChan:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Resize changes the number of workers. Extra workers exit after finishing their current task.
+func (p *Pool) Resize(n int) error {
+	if n <= 0 {
+		return ErrInvalidWorkersCount
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	if n > len(p.stops) {
+		p.addWorkers(n - len(p.stops))
+		return nil
+	}
+
+	for _, stop := range p.stops[n:] {
+		close(stop)
+	}
+	p.stops = p.stops[:n]
+
+	return nil
+}
+
+// Shutdown stops accepting tasks and waits until queued and running tasks are finished.
+// If ctx is done first, queued tasks are abandoned and ctx error is returned
+// without waiting for running tasks.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.shutdownChan)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.abandonOnce.Do(func() { close(p.abandonChan) })
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	workers := len(p.stops)
+	p.mu.Unlock()
+
+	return PoolStats{
+		Workers:       workers,
+		ActiveWorkers: int(p.active.Load()),
+		QueueDepth:    int(p.queueDepth.Load()),
+		Completed:     p.completed.Load(),
+		Failed:        p.failed.Load(),
+		Abandoned:     p.abandoned.Load(),
+	}
+}
+
+// addWorkers must be called with p.mu held.
+func (p *Pool) addWorkers(n int) {
+	p.wg.Add(n)
+	for range n {
+		stop := make(chan struct{})
+		p.stops = append(p.stops, stop)
+		go p.worker(stop)
+	}
+}
+
+func (p *Pool) worker(stop <-chan struct{}) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case task, ok := <-p.workChan:
+			if !ok {
+				return
+			}
+
+			p.active.Add(1)
+			_, err := p.o.runTask(task, p.abandonChan)
+			p.active.Add(-1)
+
+			p.completed.Add(1)
+			if err != nil {
+				p.failed.Add(1)
+			}
+		}
+	}
+}
+
+// dispatch owns the queue: it accepts submitted tasks while there is a free place
+// and hands them to workers by priority, respecting the rate limit.
+func (p *Pool) dispatch() {
+	defer p.wg.Done()
+	defer close(p.workChan)
+
+	queue := &taskQueue{}
+	limiter := p.o.newLimiter()
+	submitChan, shutdownChan := p.submitChan, p.shutdownChan
+	// wakeupChan fires when the limiter is expected to have a token
+	var wakeupChan <-chan time.Time
+
+	for {
+		if shutdownChan == nil && queue.Len() == 0 {
+			return
+		}
+
+		var (
+			workChan chan Task
+			head     Task
+		)
+		if queue.Len() > 0 {
+			if d := limiter.delayOrZero(); d == 0 {
+				workChan, head = p.workChan, queue.items[0].task
+			} else if wakeupChan == nil {
+				wakeupChan = p.o.clock.After(d)
+			}
+		}
+
+		acceptChan := submitChan
+		if queue.Len() >= p.queueSize {
+			acceptChan = nil
+		}
+
+		select {
+		case task := <-acceptChan:
+			queue.push(task)
+			p.queueDepth.Add(1)
+		case workChan <- head:
+			limiter.takeIfLimited()
+			heap.Pop(queue)
+			p.queueDepth.Add(-1)
+		case <-wakeupChan:
+			wakeupChan = nil
+		case <-shutdownChan:
+			// drain the queue without accepting new tasks
+			submitChan, shutdownChan = nil, nil
+		case <-p.abandonChan:
+			p.abandoned.Add(int64(queue.Len()))
+			p.queueDepth.Add(-int64(queue.Len()))
+			return
+		}
+	}
+}
+
+type queuedTask struct {
+	task     Task
+	priority int
+	// seq keeps FIFO order of tasks with equal priority
+	seq uint64
+}
+
+// taskQueue is a priority queue of tasks implementing heap.Interface.
+type taskQueue struct {
+	items []queuedTask
+	seq   uint64
+}
+
+func (q *taskQueue) push(t queuedTask) {
+	t.seq = q.seq
+	q.seq++
+	heap.Push(q, t)
+}
+
+func (q *taskQueue) Len() int {
+	return len(q.items)
+}
+
+func (q *taskQueue) Less(i, j int) bool {
+	if q.items[i].priority != q.items[j].priority {
+		return q.items[i].priority > q.items[j].priority
+	}
+
+	return q.items[i].seq < q.items[j].seq
+}
+
+func (q *taskQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+}
+
+func (q *taskQueue) Push(x any) {
+	q.items = append(q.items, x.(queuedTask))
+}
+
+func (q *taskQueue) Pop() any {
+	n := len(q.items)
+	item := q.items[n-1]
+	q.items[n-1] = queuedTask{}
+	q.items = q.items[:n-1]
+
+	return item
+}
@@ -0,0 +1,154 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestPool(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("submitted tasks are completed", func(t *testing.T) {
+		pool, err := NewPool(4, 10)
+		require.NoError(t, err)
+
+		var runTasksCount atomic.Int32
+		errTask := errors.New("task error")
+		for i := range 50 {
+			err := pool.Submit(context.Background(), func() error {
+				runTasksCount.Add(1)
+				if i%10 == 0 {
+					return errTask
+				}
+				return nil
+			})
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, pool.Shutdown(context.Background()))
+		require.Equal(t, int32(50), runTasksCount.Load())
+
+		stats := pool.Stats()
+		require.Equal(t, int64(50), stats.Completed)
+		require.Equal(t, int64(5), stats.Failed)
+		require.Equal(t, 0, stats.QueueDepth)
+		require.Equal(t, 0, stats.ActiveWorkers)
+	})
+
+	t.Run("submit blocks when queue is full", func(t *testing.T) {
+		pool, err := NewPool(1, 2)
+		require.NoError(t, err)
+
+		release := make(chan struct{})
+		blocking := func() error {
+			<-release
+			return nil
+		}
+
+		// one task is taken by the worker, two are waiting in the queue
+		for range 3 {
+			require.NoError(t, pool.Submit(context.Background(), blocking))
+		}
+		require.Eventually(t, func() bool {
+			stats := pool.Stats()
+			return stats.ActiveWorkers == 1 && stats.QueueDepth == 2
+		}, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, pool.Submit(ctx, blocking), context.DeadlineExceeded)
+
+		close(release)
+		require.NoError(t, pool.Shutdown(context.Background()))
+		require.Equal(t, int64(3), pool.Stats().Completed)
+	})
+
+	t.Run("resize changes concurrency", func(t *testing.T) {
+		pool, err := NewPool(1, 100)
+		require.NoError(t, err)
+
+		var (
+			mu            sync.Mutex
+			current       int
+			maxConcurrent int
+		)
+		release := make(chan struct{})
+		task := func() error {
+			mu.Lock()
+			current++
+			maxConcurrent = max(maxConcurrent, current)
+			mu.Unlock()
+
+			<-release
+
+			mu.Lock()
+			current--
+			mu.Unlock()
+			return nil
+		}
+
+		for range 10 {
+			require.NoError(t, pool.Submit(context.Background(), task))
+		}
+
+		require.NoError(t, pool.Resize(5))
+		require.Equal(t, 5, pool.Stats().Workers)
+		require.Eventually(t, func() bool {
+			return pool.Stats().ActiveWorkers == 5
+		}, time.Second, time.Millisecond)
+
+		require.NoError(t, pool.Resize(2))
+		require.Equal(t, 2, pool.Stats().Workers)
+		require.ErrorIs(t, pool.Resize(0), ErrInvalidWorkersCount)
+
+		close(release)
+		require.NoError(t, pool.Shutdown(context.Background()))
+		require.Equal(t, 5, maxConcurrent)
+		require.Equal(t, int64(10), pool.Stats().Completed)
+	})
+
+	t.Run("shutdown deadline abandons queued tasks", func(t *testing.T) {
+		pool, err := NewPool(1, 10)
+		require.NoError(t, err)
+
+		release := make(chan struct{})
+		for range 5 {
+			require.NoError(t, pool.Submit(context.Background(), func() error {
+				<-release
+				return nil
+			}))
+		}
+		require.Eventually(t, func() bool {
+			return pool.Stats().QueueDepth == 4
+		}, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+		require.ErrorIs(t, pool.Submit(context.Background(), func() error { return nil }), ErrPoolClosed)
+		require.ErrorIs(t, pool.Resize(2), ErrPoolClosed)
+
+		close(release)
+		require.NoError(t, pool.Shutdown(context.Background()))
+
+		stats := pool.Stats()
+		require.Equal(t, int64(1), stats.Completed)
+		require.Equal(t, int64(4), stats.Abandoned)
+		require.Equal(t, 0, stats.QueueDepth)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := NewPool(0, 1)
+		require.ErrorIs(t, err, ErrInvalidWorkersCount)
+
+		_, err = NewPool(1, 0)
+		require.ErrorIs(t, err, ErrInvalidQueueSize)
+	})
+}
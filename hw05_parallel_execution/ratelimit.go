@@ -0,0 +1,80 @@
+package hw05parallelexecution
+
+import (
+	"time"
+)
+
+// tokenBucket limits the rate of task dispatching.
+// It is not safe for concurrent use, only the dispatching goroutine takes tokens.
+type tokenBucket struct {
+	clock  Clock
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(clock Clock, perSecond float64, burst int) *tokenBucket {
+	burst = max(burst, 1)
+
+	return &tokenBucket{
+		clock:  clock,
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// delay returns the time until a token is available, zero if it is available now.
+func (b *tokenBucket) delay() time.Duration {
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take consumes a token, it must be called after delay returned zero.
+func (b *tokenBucket) take() {
+	b.tokens--
+}
+
+// delayOrZero is delay for a possibly nil bucket, nil bucket does not limit the rate.
+func (b *tokenBucket) delayOrZero() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	return b.delay()
+}
+
+// takeIfLimited is take for a possibly nil bucket.
+func (b *tokenBucket) takeIfLimited() {
+	if b != nil {
+		b.take()
+	}
+}
+
+// wait blocks until a token is taken or stop is closed, in the latter case it returns false.
+func (b *tokenBucket) wait(stop <-chan struct{}) bool {
+	for {
+		d := b.delay()
+		if d == 0 {
+			b.take()
+			return true
+		}
+
+		select {
+		case <-stop:
+			return false
+		case <-b.clock.After(d):
+		}
+	}
+}
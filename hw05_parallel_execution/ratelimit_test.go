@@ -0,0 +1,119 @@
+package hw05parallelexecution
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRunWithRateLimit(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("tasks are started not faster than the rate", func(t *testing.T) {
+		clock := newFakeClock()
+		var runTasksCount atomic.Int32
+		tasks := make([]Task, 5)
+		for i := range tasks {
+			tasks[i] = func() error {
+				runTasksCount.Add(1)
+				return nil
+			}
+		}
+
+		err := Run(tasks, 2, 1, WithClock(clock), WithRateLimit(10, 2))
+		require.NoError(t, err)
+		require.Equal(t, int32(5), runTasksCount.Load())
+		// two tasks are started by the burst, others wait for a token
+		require.Equal(t, []time.Duration{
+			100 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond,
+		}, clock.Delays())
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		err := Run([]Task{func() error { return nil }}, 1, 1, WithRateLimit(-1, 1))
+		require.ErrorIs(t, err, ErrInvalidRateLimit)
+	})
+}
+
+func TestRunWithPriority(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	tasks := make([]Task, 6)
+	for i := range tasks {
+		tasks[i] = func() error {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// odd tasks have higher priority
+	err := Run(tasks, 1, 1, WithPriority(func(index int) int { return index % 2 }))
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3, 5, 0, 2, 4}, order)
+}
+
+func TestPoolScheduling(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	t.Run("higher priority tasks submitted later run first", func(t *testing.T) {
+		pool, err := NewPool(1, 10)
+		require.NoError(t, err)
+
+		var (
+			mu    sync.Mutex
+			order []string
+		)
+		record := func(name string) Task {
+			return func() error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return nil
+			}
+		}
+
+		release := make(chan struct{})
+		require.NoError(t, pool.Submit(context.Background(), func() error {
+			<-release
+			return nil
+		}))
+		require.Eventually(t, func() bool {
+			return pool.Stats().ActiveWorkers == 1
+		}, time.Second, time.Millisecond)
+
+		ctx := context.Background()
+		require.NoError(t, pool.Submit(ctx, record("low 1")))
+		require.NoError(t, pool.Submit(ctx, record("low 2")))
+		require.NoError(t, pool.SubmitPriority(ctx, record("high"), 10))
+		require.NoError(t, pool.SubmitPriority(ctx, record("middle"), 5))
+
+		close(release)
+		require.NoError(t, pool.Shutdown(ctx))
+		require.Equal(t, []string{"high", "middle", "low 1", "low 2"}, order)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		clock := newFakeClock()
+		pool, err := NewPool(2, 10, WithClock(clock), WithRateLimit(1, 1))
+		require.NoError(t, err)
+
+		for range 3 {
+			require.NoError(t, pool.Submit(context.Background(), func() error { return nil }))
+		}
+
+		require.NoError(t, pool.Shutdown(context.Background()))
+		require.Equal(t, int64(3), pool.Stats().Completed)
+		require.Equal(t, []time.Duration{time.Second, time.Second}, clock.Delays())
+	})
+}
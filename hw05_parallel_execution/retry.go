@@ -0,0 +1,78 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const defaultBackoffMultiplier = 2
+
+// RetryPolicy describes how a failed task is retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after every attempt, defaults to 2.
+	Multiplier float64
+	// Jitter is a fraction in [0, 1] of the delay that is randomized.
+	Jitter float64
+	// Retryable reports whether the error is worth retrying, nil means all errors are.
+	// Panics are never retried.
+	Retryable func(err error) bool
+}
+
+// backoff returns the delay before the attempt following the given one (starting from 1).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	delay := float64(p.InitialBackoff)
+	for range attempt - 1 {
+		delay *= multiplier
+		if p.MaxBackoff > 0 && delay >= float64(p.MaxBackoff) {
+			delay = float64(p.MaxBackoff)
+			break
+		}
+	}
+
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		//nolint:gosec
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return false
+	}
+
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// do runs the task until it succeeds, the error is not retryable, attempts are over
+// or stop is closed. It returns the number of attempts made and the last error.
+func (p *RetryPolicy) do(task Task, clock Clock, stop <-chan struct{}) (attempts int, err error) {
+	for {
+		attempts++
+		err = recoverTask(task)
+		if err == nil || attempts >= p.MaxAttempts || !p.retryable(err) {
+			return attempts, err
+		}
+
+		select {
+		case <-stop:
+			return attempts, err
+		case <-clock.After(p.backoff(attempts)):
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package hw05parallelexecution
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+// fakeClock moves its time forward instead of sleeping and records every requested delay.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.delays = append(c.delays, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *fakeClock) Delays() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]time.Duration(nil), c.delays...)
+}
+
+func TestRunWithRetry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	errFlaky := errors.New("flaky")
+
+	t.Run("flaky task succeeds after retries", func(t *testing.T) {
+		clock := newFakeClock()
+		var calls atomic.Int32
+		tasks := []Task{func() error {
+			if calls.Add(1) < 3 {
+				return errFlaky
+			}
+			return nil
+		}}
+
+		err := Run(tasks, 1, 1, WithClock(clock), WithRetry(RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: 10 * time.Millisecond,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, int32(3), calls.Load())
+		require.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, clock.Delays())
+	})
+
+	t.Run("exponential backoff is capped", func(t *testing.T) {
+		clock := newFakeClock()
+		tasks := []Task{func() error { return errFlaky }}
+
+		err := Run(tasks, 1, 1, WithClock(clock), WithRetry(RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     50 * time.Millisecond,
+			Multiplier:     3,
+		}))
+
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.ErrorIs(t, err, errFlaky)
+		require.Len(t, runErr.Errors, 1)
+		require.Equal(t, 5, runErr.Errors[0].Attempts)
+		require.Equal(t, 140*time.Millisecond, runErr.Errors[0].Duration)
+		require.Equal(t, []time.Duration{
+			10 * time.Millisecond,
+			30 * time.Millisecond,
+			50 * time.Millisecond,
+			50 * time.Millisecond,
+		}, clock.Delays())
+	})
+
+	t.Run("not retryable error is counted immediately", func(t *testing.T) {
+		clock := newFakeClock()
+		errFatal := errors.New("fatal")
+		var calls atomic.Int32
+		tasks := []Task{func() error {
+			calls.Add(1)
+			return errFatal
+		}}
+
+		err := Run(tasks, 1, 1, WithClock(clock), WithRetry(RetryPolicy{
+			MaxAttempts:    5,
+			InitialBackoff: time.Millisecond,
+			Retryable: func(err error) bool {
+				return errors.Is(err, errFlaky)
+			},
+		}))
+		require.ErrorIs(t, err, errFatal)
+		require.Equal(t, int32(1), calls.Load())
+		require.Empty(t, clock.Delays())
+	})
+
+	t.Run("jitter keeps delay within bounds", func(t *testing.T) {
+		policy := RetryPolicy{
+			InitialBackoff: 100 * time.Millisecond,
+			Jitter:         0.5,
+		}
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			base := policy.InitialBackoff << (attempt - 1)
+			for range 100 {
+				delay := policy.backoff(attempt)
+				require.GreaterOrEqual(t, delay, base/2)
+				require.LessOrEqual(t, delay, base)
+			}
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 var (
@@ -13,49 +14,162 @@ var (
 
 type Task func() error
 
+// indexedTask keeps the position of the task in the input slice to report failures.
+type indexedTask struct {
+	index int
+	task  Task
+}
+
 // Run starts tasks in n goroutines and stops its work when receiving m errors from tasks.
-func Run(tasks []Task, n, m int) error {
-	if m <= 0 {
-		return ErrErrorsLimitExceeded
+// The limit m <= 0 means Run fails immediately, WithErrorPolicy option replaces m with another policy.
+// If the limit is exceeded, the returned error is *RunError with the failures of completed tasks.
+// Panics in tasks are recovered and counted as *PanicError.
+func Run(tasks []Task, n, m int, opts ...Option) error {
+	o := newOptions(opts)
+	o.workers = n
+	if o.errorPolicy == nil {
+		policy := MaxErrors(m)
+		o.errorPolicy = &policy
 	}
-
-	if n <= 0 {
-		return ErrInvalidWorkersCount
+	if err := o.validate(); err != nil {
+		return err
 	}
 
-	taskChan := make(chan Task)
-	wg := &sync.WaitGroup{}
-	var errorsCount atomic.Int64
+	taskChan := make(chan indexedTask)
+	e := newExecutor(o)
 
 	// little optimisation when workers count (n) > tasks count
-	workersCount := min(n, len(tasks))
-	wg.Add(workersCount)
+	wait := e.startWorkers(min(n, len(tasks)), taskChan, nil)
+
+	limiter := o.newLimiter()
+	for _, i := range o.dispatchOrder(len(tasks)) {
+		if limiter != nil && !limiter.wait(e.stop) {
+			break
+		}
+
+		taskChan <- indexedTask{index: i, task: tasks[i]}
+		if e.stopped() {
+			break
+		}
+	}
 
-	for range workersCount {
+	close(taskChan)
+	wait()
+
+	return e.err()
+}
+
+// executor runs tasks on workers and counts their errors against the limit.
+type executor struct {
+	o        *options
+	policy   ErrorPolicy
+	exceeded atomic.Bool
+	panicked atomic.Bool
+
+	// stop is closed when the run is stopped to interrupt retries
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu        sync.Mutex
+	failures  []*TaskError
+	succeeded int
+}
+
+func newExecutor(o *options) *executor {
+	return &executor{
+		o:      o,
+		policy: o.policy(),
+		stop:   make(chan struct{}),
+	}
+}
+
+// startWorkers launches n workers processing tasks until the channel is closed.
+// The optional report is called by a worker after every task. The returned func waits for workers to exit.
+func (e *executor) startWorkers(n int, tasks <-chan indexedTask, report func(index int, err error)) (wait func()) {
+	wg := &sync.WaitGroup{}
+	wg.Add(n)
+
+	for range n {
 		go func() {
 			defer wg.Done()
 
-			for task := range taskChan {
-				if err := task(); err != nil {
-					errorsCount.Add(1)
+			for it := range tasks {
+				err := e.execute(it)
+				if report != nil {
+					report(it.index, err)
 				}
 			}
 		}()
 	}
 
-	for _, task := range tasks {
-		taskChan <- task
-		if errorsCount.Load() >= int64(m) {
-			break
-		}
+	return wg.Wait
+}
+
+func (e *executor) execute(it indexedTask) error {
+	start := e.o.clock.Now()
+
+	attempts, err := e.o.runTask(it.task, e.stop)
+	if e.add(it.index, err, attempts, e.o.clock.Now().Sub(start)) {
+		e.exceeded.Store(true)
+		e.abort()
 	}
 
-	close(taskChan)
-	wg.Wait()
+	var panicErr *PanicError
+	if e.o.panicPolicy == PanicAbort && errors.As(err, &panicErr) {
+		e.panicked.Store(true)
+		e.abort()
+	}
+
+	return err
+}
+
+// abort interrupts retries of running tasks.
+func (e *executor) abort() {
+	e.stopOnce.Do(func() { close(e.stop) })
+}
+
+func (e *executor) limitExceeded() bool {
+	return e.exceeded.Load()
+}
+
+// stopped reports whether new tasks must not be started.
+func (e *executor) stopped() bool {
+	return e.limitExceeded() || e.panicked.Load()
+}
+
+// add records the outcome of the task and reports whether the error policy is exceeded.
+func (e *executor) add(index int, err error, attempts int, duration time.Duration) (exceeded bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	if errorsCount.Load() >= int64(m) {
-		return ErrErrorsLimitExceeded
+	if err == nil {
+		e.succeeded++
+	} else {
+		e.failures = append(e.failures, &TaskError{
+			Index:    index,
+			Err:      err,
+			Attempts: attempts,
+			Duration: duration,
+		})
 	}
 
-	return nil
+	return e.policy.exceeded(len(e.failures), len(e.failures)+e.succeeded)
+}
+
+// err returns *RunError if the run was stopped, nil otherwise.
+func (e *executor) err() error {
+	var reason error
+	switch {
+	case e.limitExceeded():
+		reason = ErrErrorsLimitExceeded
+	case e.panicked.Load():
+		reason = ErrTaskPanicked
+	default:
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return newRunError(reason, e.failures, e.succeeded)
 }
@@ -70,6 +70,32 @@ func TestRun(t *testing.T) {
 		require.LessOrEqual(t, int64(elapsedTime), int64(sumTime/2), "tasks were run sequentially?")
 	})
 
+	t.Run("errors limit exceeded returns task errors", func(t *testing.T) {
+		errEven := errors.New("even task failed")
+		tasks := make([]Task, 10)
+		for i := range tasks {
+			tasks[i] = func() error {
+				if i%2 == 0 {
+					return fmt.Errorf("task %d: %w", i, errEven)
+				}
+				return nil
+			}
+		}
+
+		err := Run(tasks, 1, 3)
+
+		var runErr *RunError
+		require.ErrorAs(t, err, &runErr)
+		require.ErrorIs(t, err, ErrErrorsLimitExceeded)
+		require.ErrorIs(t, err, errEven)
+		require.Equal(t, []int{0, 2, 4}, runErr.Failed())
+		require.GreaterOrEqual(t, runErr.Succeeded, 2)
+
+		var taskErr *TaskError
+		require.ErrorAs(t, err, &taskErr)
+		require.Equal(t, 0, taskErr.Index)
+	})
+
 	t.Run("m is equal zero", func(t *testing.T) {
 		err := Run(nil, 0, 0)
 		require.Truef(t, errors.Is(err, ErrErrorsLimitExceeded), "actual err - %v", err)
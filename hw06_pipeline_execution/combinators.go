@@ -0,0 +1,263 @@
+package hw06pipelineexecution
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Filter returns a stage passing only values satisfying the predicate.
+func Filter(done In, pred func(v interface{}) bool) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				if !pred(v) {
+					continue
+				}
+				if !send(done, out, v) {
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// FlatMap returns a stage sending every value returned by fn for an input value.
+func FlatMap(done In, fn func(v interface{}) []interface{}) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				for _, res := range fn(v) {
+					if !send(done, out, res) {
+						return
+					}
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Batch returns a stage grouping values into []interface{} of up to size values.
+// An incomplete batch is sent when maxWait passed since its first value or the input is closed.
+// Zero maxWait means waiting for a full batch.
+func Batch(done In, size int, maxWait time.Duration) Stage {
+	size = max(size, 1)
+
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+
+			batch := make([]interface{}, 0, size)
+			timer := time.NewTimer(maxWait)
+			stopTimer(timer)
+			defer timer.Stop()
+			// timeout is nil while the batch is empty or maxWait is not set
+			var timeout <-chan time.Time
+
+			flush := func() bool {
+				timeout = nil
+				stopTimer(timer)
+				if len(batch) == 0 {
+					return true
+				}
+				full := batch
+				batch = make([]interface{}, 0, size)
+				return send(done, out, full)
+			}
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-timeout:
+					if !flush() {
+						return
+					}
+				case v, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+
+					batch = append(batch, v)
+					if len(batch) == 1 && maxWait > 0 {
+						timer.Reset(maxWait)
+						timeout = timer.C
+					}
+					if len(batch) == size && !flush() {
+						return
+					}
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Window returns a stage grouping values received during every period into []interface{}.
+// Empty windows are not sent, the last window is sent when the input is closed.
+// Non-positive period means a single window sent when the input is closed.
+func Window(done In, period time.Duration) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+
+			// ticks is nil if the period is not positive
+			var ticks <-chan time.Time
+			if period > 0 {
+				ticker := time.NewTicker(period)
+				defer ticker.Stop()
+				ticks = ticker.C
+			}
+
+			var window []interface{}
+			flush := func() bool {
+				if len(window) == 0 {
+					return true
+				}
+				full := window
+				window = nil
+				return send(done, out, full)
+			}
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticks:
+					if !flush() {
+						return
+					}
+				case v, ok := <-in:
+					if !ok {
+						flush()
+						return
+					}
+					window = append(window, v)
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Throttle returns a stage passing not more than perSecond values per second.
+// Values are not throttled if perSecond is not positive.
+func Throttle(done In, perSecond float64) Stage {
+	if math.IsNaN(perSecond) || perSecond <= 0 {
+		return func(in In) Out {
+			return chanWrapper(in, done)
+		}
+	}
+	interval := max(time.Duration(float64(time.Second)/perSecond), 1)
+
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			first := true
+			for v := range in {
+				if !first {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+				}
+				first = false
+
+				if !send(done, out, v) {
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+// Tee sends every value from in to n output channels, so they can be consumed by separate pipelines.
+// A value is sent to the next output only after the previous one received it,
+// so all outputs must be read until they are closed or done is closed.
+func Tee(done In, in In, n int) []Out {
+	outs := make([]Bi, n)
+	res := make([]Out, n)
+	for i := range outs {
+		outs[i] = make(Bi)
+		res[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+
+		for v := range chanWrapper(in, done) {
+			for _, out := range outs {
+				if !send(done, out, v) {
+					return
+				}
+			}
+		}
+	}()
+
+	return res
+}
+
+// Merge sends values from all input channels to the single output channel in the order they are received.
+func Merge(done In, ins ...In) Out {
+	out := make(Bi)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(ins))
+
+	for _, in := range ins {
+		go func() {
+			defer wg.Done()
+			for v := range chanWrapper(in, done) {
+				if !send(done, out, v) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// send sends the value to out unless done is closed first, it reports whether the value was sent.
+func send(done In, out Bi, v interface{}) bool {
+	select {
+	case <-done:
+		return false
+	case out <- v:
+		return true
+	}
+}
+
+// stopTimer stops the timer and drains its channel, so it can be reset.
+func stopTimer(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+}
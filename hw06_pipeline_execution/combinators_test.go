@@ -0,0 +1,148 @@
+package hw06pipelineexecution
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func collect(out Out) []interface{} {
+	result := make([]interface{}, 0)
+	for v := range out {
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestCombinators(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("filter and flat map", func(t *testing.T) {
+		even := Filter(nil, func(v interface{}) bool { return v.(int)%2 == 0 })
+		repeat := FlatMap(nil, func(v interface{}) []interface{} { return []interface{}{v, v} })
+
+		result := collect(ExecutePipeline(generate[interface{}](1, 2, 3, 4), nil, even, repeat))
+		require.Equal(t, []interface{}{2, 2, 4, 4}, result)
+	})
+
+	t.Run("batch by size", func(t *testing.T) {
+		result := collect(ExecutePipeline(generate[interface{}](1, 2, 3, 4, 5), nil, Batch(nil, 2, 0)))
+		require.Equal(t, []interface{}{
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{5},
+		}, result)
+	})
+
+	t.Run("batch by timeout", func(t *testing.T) {
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			in <- 1
+			in <- 2
+			time.Sleep(sleepPerStage)
+			in <- 3
+		}()
+
+		result := collect(ExecutePipeline(in, nil, Batch(nil, 10, sleepPerStage/4)))
+		require.Equal(t, []interface{}{
+			[]interface{}{1, 2},
+			[]interface{}{3},
+		}, result)
+	})
+
+	t.Run("window", func(t *testing.T) {
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			in <- 1
+			in <- 2
+			time.Sleep(sleepPerStage * 3 / 2)
+			in <- 3
+		}()
+
+		result := collect(ExecutePipeline(in, nil, Window(nil, sleepPerStage)))
+		require.Equal(t, []interface{}{
+			[]interface{}{1, 2},
+			[]interface{}{3},
+		}, result)
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		start := time.Now()
+		result := collect(ExecutePipeline(generate[interface{}](1, 2, 3, 4, 5), nil, Throttle(nil, 50)))
+		elapsed := time.Since(start)
+
+		require.Equal(t, []interface{}{1, 2, 3, 4, 5}, result)
+		// 4 intervals of 20ms between 5 values
+		require.GreaterOrEqual(t, elapsed, 80*time.Millisecond)
+	})
+
+	t.Run("non-positive parameters", func(t *testing.T) {
+		for _, perSecond := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+			result := collect(ExecutePipeline(generate[interface{}](1, 2, 3), nil, Throttle(nil, perSecond)))
+			require.Equal(t, []interface{}{1, 2, 3}, result)
+		}
+
+		result := collect(ExecutePipeline(generate[interface{}](1, 2, 3), nil, Window(nil, 0)))
+		require.Equal(t, []interface{}{[]interface{}{1, 2, 3}}, result)
+	})
+
+	t.Run("tee and merge", func(t *testing.T) {
+		outs := Tee(nil, generate[interface{}](1, 2, 3), 2)
+		pass := Filter(nil, func(interface{}) bool { return true })
+
+		result := collect(Merge(nil,
+			ExecutePipeline(outs[0], nil, pass),
+			ExecutePipeline(outs[1], nil, pass),
+		))
+
+		ints := make([]int, 0, len(result))
+		for _, v := range result {
+			ints = append(ints, v.(int))
+		}
+		sort.Ints(ints)
+		require.Equal(t, []int{1, 1, 2, 2, 3, 3}, ints)
+	})
+
+	t.Run("done stops all combinators", func(t *testing.T) {
+		done := make(Bi)
+		in := make(Bi)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(in)
+			for i := 0; ; i++ {
+				select {
+				case in <- i:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		outs := Tee(done, in, 2)
+		first := ExecutePipeline(outs[0], done,
+			Throttle(done, 1000),
+			Batch(done, 3, time.Second),
+			FlatMap(done, func(v interface{}) []interface{} { return v.([]interface{}) }),
+		)
+		second := ExecutePipeline(outs[1], done,
+			Window(done, time.Second),
+			Filter(done, func(interface{}) bool { return true }),
+		)
+		merged := Merge(done, first, second)
+
+		time.AfterFunc(sleepPerStage, func() { close(done) })
+		//nolint:revive
+		for range merged {
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,49 @@
+package hw06pipelineexecution
+
+import "context"
+
+// ContextStage is a stage receiving the context of the pipeline, it should stop when the context is done.
+type ContextStage func(ctx context.Context, in In) (out Out)
+
+// PipelineStage is a stage of ExecutePipelineContext: a Stage or a ContextStage.
+type PipelineStage interface {
+	start(ctx context.Context, in In) Out
+	isNil() bool
+}
+
+func (s Stage) start(_ context.Context, in In) Out {
+	return s(in)
+}
+
+func (s Stage) isNil() bool {
+	return s == nil
+}
+
+func (s ContextStage) start(ctx context.Context, in In) Out {
+	return s(ctx, in)
+}
+
+func (s ContextStage) isNil() bool {
+	return s == nil
+}
+
+// ExecutePipelineContext runs stages like ExecutePipeline, the pipeline is stopped when ctx is done.
+// The returned function drains unread output and returns the cause of the context cancellation
+// if the pipeline was stopped by ctx, or nil otherwise.
+func ExecutePipelineContext(ctx context.Context, in In, stages ...PipelineStage) (Out, func() error) {
+	out, e := execute(ctx, in, ctx.Done(), stages)
+
+	wait := func() error {
+		//nolint:revive
+		for range out {
+		}
+		<-e.finished
+
+		if e.stopped.Load() {
+			return context.Cause(ctx)
+		}
+		return nil
+	}
+
+	return out, wait
+}
@@ -0,0 +1,97 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestExecutePipelineContext(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	double := Stage(func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				out <- v.(int) * 2
+			}
+		}()
+		return out
+	})
+
+	// increment receives the context and stops sending when it is done
+	increment := ContextStage(func(ctx context.Context, in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				select {
+				case out <- v.(int) + 1:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return out
+	})
+
+	t.Run("mixed stages", func(t *testing.T) {
+		out, wait := ExecutePipelineContext(context.Background(), generate[interface{}](1, 2, 3), double, increment)
+
+		require.Equal(t, []interface{}{3, 5, 7}, collect(out))
+		require.NoError(t, wait())
+	})
+
+	t.Run("cancellation cause", func(t *testing.T) {
+		errStop := errors.New("stop")
+		ctx, cancel := context.WithCancelCause(context.Background())
+
+		in := make(Bi)
+		go func() {
+			defer close(in)
+			for i := 0; ; i++ {
+				select {
+				case in <- i:
+					time.Sleep(sleepPerStage / 10)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		out, wait := ExecutePipelineContext(ctx, in, double, increment)
+		time.AfterFunc(sleepPerStage, func() { cancel(errStop) })
+
+		require.NotEmpty(t, collect(out))
+		require.ErrorIs(t, wait(), errStop)
+	})
+
+	t.Run("wait drains unread output", func(t *testing.T) {
+		_, wait := ExecutePipelineContext(context.Background(), generate[interface{}](1, 2, 3), increment)
+		require.NoError(t, wait())
+	})
+
+	t.Run("finished before cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		out, wait := ExecutePipelineContext(ctx, generate[interface{}](1, 2, 3), double)
+
+		require.Equal(t, []interface{}{2, 4, 6}, collect(out))
+		cancel(errors.New("stop"))
+		require.NoError(t, wait())
+	})
+
+	t.Run("nil stages", func(t *testing.T) {
+		out, wait := ExecutePipelineContext(context.Background(), generate[interface{}](1, 2), Stage(nil), nil)
+		require.Equal(t, []interface{}{1, 2}, collect(out))
+		require.NoError(t, wait())
+
+		out, wait = ExecutePipelineContext(context.Background(), make(Bi), ContextStage(nil))
+		require.Empty(t, collect(out))
+		require.NoError(t, wait())
+	})
+}
@@ -0,0 +1,125 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStage processes a single value, an error fails the value and, depending on ErrorMode, the pipeline.
+type ErrStage func(ctx context.Context, v interface{}) (interface{}, error)
+
+// ErrorMode defines how ExecutePipelineErr reacts on stage errors.
+type ErrorMode int
+
+const (
+	// StopOnFirstError cancels the pipeline on the first error.
+	StopOnFirstError ErrorMode = iota
+	// CollectErrors drops failed values and keeps the pipeline running, all errors are returned by wait.
+	CollectErrors
+)
+
+// StageError is an error returned by a stage for a value.
+type StageError struct {
+	// Stage is the index of the stage in the pipeline.
+	Stage int
+	Value interface{}
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %d: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// ExecutePipelineErr runs stages in separate goroutines like ExecutePipeline, ctx replaces the done channel.
+// The returned wait func blocks until all stages are finished, draining unread output values,
+// and returns the first error, all errors joined in CollectErrors mode, or the cause of ctx cancellation.
+func ExecutePipelineErr(
+	ctx context.Context,
+	in In,
+	mode ErrorMode,
+	stages ...ErrStage,
+) (out Out, wait func() error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	report := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		errs = append(errs, err)
+		if mode == StopOnFirstError && len(errs) == 1 {
+			cancel(err)
+		}
+	}
+
+	if in == nil {
+		closed := make(Bi)
+		close(closed)
+		in = closed
+	}
+
+	currentChan := in
+	for i, stage := range stages {
+		if stage == nil {
+			continue
+		}
+
+		stageIn := chanWrapper(currentChan, ctx.Done())
+		stageOut := make(Bi)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(stageOut)
+
+			for v := range stageIn {
+				res, err := stage(ctx, v)
+				if err != nil {
+					report(&StageError{Stage: i, Value: v, Err: err})
+					continue
+				}
+				stageOut <- res
+			}
+		}()
+		currentChan = stageOut
+	}
+	out = chanWrapper(currentChan, ctx.Done())
+
+	var (
+		once    sync.Once
+		waitErr error
+	)
+	return out, func() error {
+		once.Do(func() {
+			//nolint:revive
+			for range out {
+			}
+			wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case len(errs) == 0:
+				if ctx.Err() != nil {
+					waitErr = context.Cause(ctx)
+				}
+			case mode == CollectErrors:
+				waitErr = errors.Join(errs...)
+			default:
+				waitErr = errs[0]
+			}
+			cancel(nil)
+		})
+		return waitErr
+	}
+}
@@ -0,0 +1,91 @@
+package hw06pipelineexecution
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestExecutePipelineErr(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	errNegative := errors.New("negative value")
+	double := func(_ context.Context, v interface{}) (interface{}, error) {
+		time.Sleep(sleepPerStage / 10)
+		if v.(int) < 0 {
+			return nil, errNegative
+		}
+		return v.(int) * 2, nil
+	}
+	stringify := func(_ context.Context, v interface{}) (interface{}, error) {
+		return strconv.Itoa(v.(int)), nil
+	}
+
+	t.Run("without errors", func(t *testing.T) {
+		out, wait := ExecutePipelineErr(context.Background(), generate[interface{}](1, 2, 3),
+			StopOnFirstError, double, stringify)
+
+		result := make([]string, 0, 3)
+		for v := range out {
+			result = append(result, v.(string))
+		}
+		require.NoError(t, wait())
+		require.Equal(t, []string{"2", "4", "6"}, result)
+	})
+
+	t.Run("first error cancels pipeline", func(t *testing.T) {
+		data := toAny([]int{1, -2, 3, 4, 5, 6, 7, 8, 9, 10})
+		out, wait := ExecutePipelineErr(context.Background(), generate(data...),
+			StopOnFirstError, double, stringify)
+
+		result := make([]string, 0, len(data))
+		for v := range out {
+			result = append(result, v.(string))
+		}
+
+		err := wait()
+		require.ErrorIs(t, err, errNegative)
+
+		var stageErr *StageError
+		require.ErrorAs(t, err, &stageErr)
+		require.Equal(t, 0, stageErr.Stage)
+		require.Equal(t, -2, stageErr.Value)
+		require.Less(t, len(result), len(data)-1)
+	})
+
+	t.Run("collect all errors", func(t *testing.T) {
+		data := toAny([]int{1, -2, 3, -4, 5})
+		out, wait := ExecutePipelineErr(context.Background(), generate(data...),
+			CollectErrors, double, stringify)
+
+		result := make([]string, 0, len(data))
+		for v := range out {
+			result = append(result, v.(string))
+		}
+
+		err := wait()
+		require.ErrorIs(t, err, errNegative)
+		require.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+		require.Equal(t, []string{"2", "6", "10"}, result)
+	})
+
+	t.Run("parent context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		errStop := errors.New("stop")
+		cancel(errStop)
+
+		_, wait := ExecutePipelineErr(ctx, generate[interface{}](1, 2, 3), StopOnFirstError, double)
+		require.ErrorIs(t, wait(), errStop)
+	})
+
+	t.Run("wait drains unread output", func(t *testing.T) {
+		_, wait := ExecutePipelineErr(context.Background(), generate[interface{}](1, 2, 3),
+			StopOnFirstError, double)
+		require.NoError(t, wait())
+	})
+}
@@ -0,0 +1,230 @@
+package hw06pipelineexecution
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are upper bounds of latency histogram buckets.
+var DefaultLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// StageOption configures a stage of MeteredPipeline.
+type StageOption func(*stageConfig)
+
+type stageConfig struct {
+	name   string
+	buffer int
+}
+
+// WithStageName sets the name of the stage in stats, "stage N" by default.
+func WithStageName(name string) StageOption {
+	return func(c *stageConfig) {
+		c.name = name
+	}
+}
+
+// WithBuffer sets the buffer size of the stage input channel, unbuffered by default.
+func WithBuffer(size int) StageOption {
+	return func(c *stageConfig) {
+		c.buffer = max(size, 0)
+	}
+}
+
+// LatencyHistogram counts processing latencies in buckets.
+type LatencyHistogram struct {
+	// Bounds are upper bounds of buckets, the last bucket in Counts has no upper bound.
+	Bounds []time.Duration
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+}
+
+// Mean returns the average latency.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return h.Sum / time.Duration(h.Count)
+}
+
+// StageStats is a snapshot of stage metrics.
+type StageStats struct {
+	Name string
+	// In is the number of values passed to the stage.
+	In int64
+	// Out is the number of values received from the stage.
+	Out int64
+	// BlockedSend is the total time spent waiting for the stage to accept input values.
+	// A large value means the stage is slower than its upstream.
+	BlockedSend time.Duration
+	// BlockedReceive is the total time spent waiting for the stage to produce output values.
+	BlockedReceive time.Duration
+	// Latency is the processing time of a single value, it is collected only for stages added with AddFunc.
+	Latency LatencyHistogram
+}
+
+type stageMetrics struct {
+	in             atomic.Int64
+	out            atomic.Int64
+	blockedSend    atomic.Int64
+	blockedReceive atomic.Int64
+
+	mu      sync.Mutex
+	latency LatencyHistogram
+}
+
+func newStageMetrics() *stageMetrics {
+	return &stageMetrics{
+		latency: LatencyHistogram{
+			Bounds: DefaultLatencyBuckets,
+			Counts: make([]int64, len(DefaultLatencyBuckets)+1),
+		},
+	}
+}
+
+func (m *stageMetrics) observe(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := 0
+	for i < len(m.latency.Bounds) && d > m.latency.Bounds[i] {
+		i++
+	}
+	m.latency.Counts[i]++
+	m.latency.Count++
+	m.latency.Sum += d
+}
+
+func (m *stageMetrics) snapshot(name string) StageStats {
+	m.mu.Lock()
+	latency := m.latency
+	latency.Counts = append([]int64(nil), m.latency.Counts...)
+	m.mu.Unlock()
+
+	return StageStats{
+		Name:           name,
+		In:             m.in.Load(),
+		Out:            m.out.Load(),
+		BlockedSend:    time.Duration(m.blockedSend.Load()),
+		BlockedReceive: time.Duration(m.blockedReceive.Load()),
+		Latency:        latency,
+	}
+}
+
+type meteredStage struct {
+	config  stageConfig
+	stage   Stage
+	metrics *stageMetrics
+}
+
+// MeteredPipeline is a pipeline collecting metrics of every stage to find bottlenecks.
+type MeteredPipeline struct {
+	stages []*meteredStage
+}
+
+func NewMeteredPipeline() *MeteredPipeline {
+	return &MeteredPipeline{}
+}
+
+// Add appends the stage to the pipeline, nil stages are skipped.
+func (p *MeteredPipeline) Add(stage Stage, opts ...StageOption) *MeteredPipeline {
+	if stage != nil {
+		p.add(stage, opts)
+	}
+
+	return p
+}
+
+// AddFunc appends a stage applying fn to every value, the processing latency of fn is collected.
+func (p *MeteredPipeline) AddFunc(fn func(v interface{}) interface{}, opts ...StageOption) *MeteredPipeline {
+	s := p.add(nil, opts)
+	s.stage = func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				start := time.Now()
+				res := fn(v)
+				s.metrics.observe(time.Since(start))
+				out <- res
+			}
+		}()
+		return out
+	}
+
+	return p
+}
+
+func (p *MeteredPipeline) add(stage Stage, opts []StageOption) *meteredStage {
+	config := stageConfig{name: "stage " + strconv.Itoa(len(p.stages))}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	s := &meteredStage{
+		config:  config,
+		stage:   stage,
+		metrics: newStageMetrics(),
+	}
+	p.stages = append(p.stages, s)
+
+	return s
+}
+
+// Execute runs the pipeline like ExecutePipeline, the pipeline must not be executed concurrently.
+func (p *MeteredPipeline) Execute(in In, done In) Out {
+	if in == nil || len(p.stages) == 0 {
+		outputChan := make(Bi)
+		close(outputChan)
+		return outputChan
+	}
+
+	currentChan := in
+	var prev *stageMetrics
+	for _, s := range p.stages {
+		stageIn := hookedChanWrapper(currentChan, done, meteredHooks(s.config.buffer, prev, s.metrics))
+		currentChan = s.stage(stageIn)
+		prev = s.metrics
+	}
+
+	return hookedChanWrapper(currentChan, done, meteredHooks(0, prev, nil))
+}
+
+// Stats returns the current metrics of all stages in the pipeline order.
+func (p *MeteredPipeline) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(p.stages))
+	for _, s := range p.stages {
+		stats = append(stats, s.metrics.snapshot(s.config.name))
+	}
+
+	return stats
+}
+
+// meteredHooks returns hooks of a wrapper recording metrics of the stage producing its input (from)
+// and the stage consuming its output (to). Both of them may be nil.
+func meteredHooks(buffer int, from, to *stageMetrics) wrapperHooks {
+	hooks := wrapperHooks{buffer: buffer}
+	if from != nil {
+		hooks.received = func(blocked time.Duration) {
+			from.blockedReceive.Add(int64(blocked))
+			from.out.Add(1)
+		}
+	}
+	if to != nil {
+		hooks.sent = func(blocked time.Duration) {
+			to.blockedSend.Add(int64(blocked))
+			to.in.Add(1)
+		}
+	}
+
+	return hooks
+}
@@ -0,0 +1,66 @@
+package hw06pipelineexecution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMeteredPipeline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	t.Run("stats show the bottleneck", func(t *testing.T) {
+		const slowDelay = 20 * time.Millisecond
+		data := toAny([]int{1, 2, 3, 4, 5})
+
+		p := NewMeteredPipeline().
+			AddFunc(func(v interface{}) interface{} { return v.(int) + 1 }, WithStageName("fast")).
+			AddFunc(func(v interface{}) interface{} {
+				time.Sleep(slowDelay)
+				return v.(int) * 2
+			}, WithStageName("slow"), WithBuffer(2)).
+			Add(Filter(nil, func(v interface{}) bool { return v.(int) > 6 }))
+
+		result := collect(p.Execute(generate(data...), nil))
+		require.Equal(t, []interface{}{8, 10, 12}, result)
+
+		stats := p.Stats()
+		require.Len(t, stats, 3)
+		require.Equal(t, "fast", stats[0].Name)
+		require.Equal(t, "slow", stats[1].Name)
+		require.Equal(t, "stage 2", stats[2].Name)
+
+		for _, s := range stats[:2] {
+			require.Equal(t, int64(len(data)), s.In, s.Name)
+			require.Equal(t, int64(len(data)), s.Out, s.Name)
+			require.Equal(t, int64(len(data)), s.Latency.Count, s.Name)
+		}
+		require.Equal(t, int64(len(data)), stats[2].In)
+		require.Equal(t, int64(3), stats[2].Out)
+		require.Zero(t, stats[2].Latency.Count)
+
+		// values wait for the slow stage to accept them
+		require.Greater(t, stats[1].BlockedSend, slowDelay)
+		require.Greater(t, stats[1].BlockedSend, stats[0].BlockedSend)
+		require.Greater(t, stats[1].BlockedSend, stats[2].BlockedSend)
+		require.GreaterOrEqual(t, stats[1].Latency.Mean(), slowDelay)
+		// 20ms latencies are in (10ms, 100ms] bucket
+		require.Equal(t, int64(len(data)), stats[1].Latency.Counts[3])
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		close(done)
+
+		p := NewMeteredPipeline().AddFunc(func(v interface{}) interface{} { return v })
+		for range 100 {
+			require.Empty(t, collect(p.Execute(generate(toAny([]int{1, 2, 3})...), done)))
+		}
+	})
+
+	t.Run("empty pipeline", func(t *testing.T) {
+		require.Empty(t, collect(NewMeteredPipeline().Add(nil).Execute(make(Bi), nil)))
+	})
+}
@@ -0,0 +1,105 @@
+package hw06pipelineexecution
+
+import "sync"
+
+// ParallelStage returns a stage applying fn to values in the given number of goroutines.
+// If ordered is true, output values keep the order of input ones, otherwise they are sent as soon as ready.
+// The stage finishes when the input channel is closed, so inside ExecutePipeline it is stopped by done.
+func ParallelStage(workers int, fn func(v interface{}) interface{}, ordered bool) Stage {
+	return Stage(Parallel(workers, fn, ordered))
+}
+
+// Parallel is a typed version of ParallelStage.
+func Parallel[I, O any](workers int, fn func(v I) O, ordered bool) TypedStage[I, O] {
+	workers = max(workers, 1)
+
+	if ordered {
+		return func(in <-chan I) <-chan O {
+			return orderedFanOut(in, workers, fn)
+		}
+	}
+
+	return func(in <-chan I) <-chan O {
+		out := make(chan O)
+		wg := &sync.WaitGroup{}
+		wg.Add(workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+				for v := range in {
+					out <- fn(v)
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		return out
+	}
+}
+
+type sequenced[T any] struct {
+	seq   int
+	value T
+}
+
+// orderedFanOut processes values in workers and restores their order with a reorder buffer.
+// The number of values being processed or waiting in the buffer is limited by 2*workers.
+func orderedFanOut[I, O any](in <-chan I, workers int, fn func(v I) O) <-chan O {
+	out := make(chan O)
+	jobs := make(chan sequenced[I])
+	results := make(chan sequenced[O])
+	window := make(chan struct{}, 2*workers)
+
+	// dispatcher numbers input values
+	go func() {
+		defer close(jobs)
+		seq := 0
+		for v := range in {
+			window <- struct{}{}
+			jobs <- sequenced[I]{seq: seq, value: v}
+			seq++
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- sequenced[O]{seq: job.seq, value: fn(job.value)}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// collector sends values in order of their sequence numbers
+	go func() {
+		defer close(out)
+		buffer := make(map[int]O, 2*workers)
+		next := 0
+		for r := range results {
+			buffer[r.seq] = r.value
+			for {
+				v, ok := buffer[next]
+				if !ok {
+					break
+				}
+				delete(buffer, next)
+				out <- v
+				<-window
+				next++
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,99 @@
+package hw06pipelineexecution
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestParallelStage(t *testing.T) {
+	data := make([]int, 20)
+	for i := range data {
+		data[i] = i
+	}
+
+	// slow stage sleeps random time, so results are completed out of order
+	slow := func(v interface{}) interface{} {
+		time.Sleep(time.Millisecond * time.Duration(rand.Intn(50)))
+		return v.(int) * 2
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		result := make([]int, 0, len(data))
+		start := time.Now()
+		for v := range ExecutePipeline(generate(toAny(data)...), nil, ParallelStage(10, slow, true)) {
+			result = append(result, v.(int))
+		}
+		elapsed := time.Since(start)
+
+		expected := make([]int, len(data))
+		for i, v := range data {
+			expected[i] = v * 2
+		}
+		require.Equal(t, expected, result)
+		// sequentially it would take ~0.5s
+		require.Less(t, int64(elapsed), int64(sleepPerStage*3))
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		result := make([]int, 0, len(data))
+		for v := range ExecutePipeline(generate(toAny(data)...), nil, ParallelStage(10, slow, false)) {
+			result = append(result, v.(int))
+		}
+
+		sort.Ints(result)
+		for i, v := range result {
+			require.Equal(t, data[i]*2, v)
+		}
+		require.Len(t, result, len(data))
+	})
+
+	t.Run("typed stage in typed pipeline", func(t *testing.T) {
+		p := Then(
+			Pipe(Parallel(4, func(v int) int { return v * v }, true)),
+			Parallel(4, strconv.Itoa, true),
+		)
+
+		result := make([]string, 0, 5)
+		for s := range p.Execute(generate(1, 2, 3, 4, 5), nil) {
+			result = append(result, s)
+		}
+		require.Equal(t, []string{"1", "4", "9", "16", "25"}, result)
+	})
+
+	t.Run("done stops all workers", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		for _, ordered := range []bool{true, false} {
+			done := make(Bi)
+			go func() {
+				<-time.After(sleepPerStage)
+				close(done)
+			}()
+
+			stage := ParallelStage(5, func(v interface{}) interface{} {
+				time.Sleep(sleepPerStage / 4)
+				return v
+			}, ordered)
+
+			var count int
+			for range ExecutePipeline(generate(toAny(data)...), done, stage) {
+				count++
+			}
+			require.Less(t, count, len(data))
+		}
+	})
+}
+
+func toAny[T any](values []T) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+	return res
+}
@@ -1,5 +1,11 @@
 package hw06pipelineexecution
 
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
 type (
 	In  = <-chan interface{}
 	Out = In
@@ -8,49 +14,129 @@ type (
 
 type Stage func(in In) (out Out)
 
+// ExecutePipeline runs the stages, the output is closed when the input is exhausted or done is closed.
+// The output is closed immediately if in is nil, there are no stages or the only stage is nil,
+// otherwise nil stages are skipped.
 func ExecutePipeline(in In, done In, stages ...Stage) Out {
-	if in == nil || len(stages) == 0 || len(stages) == 1 && stages[0] == nil {
+	pipelineStages := make([]PipelineStage, 0, len(stages))
+	for _, stage := range stages {
+		pipelineStages = append(pipelineStages, stage)
+	}
+
+	out, _ := execute(context.Background(), in, done, pipelineStages)
+	return out
+}
+
+// execution is the state of a started pipeline.
+type execution struct {
+	// finished is closed when the output of the last stage is drained.
+	finished chan struct{}
+	// stopped is set when a wrapper stops on done before its input is closed.
+	stopped atomic.Bool
+}
+
+// execute starts the stages with inputs stopped by done, ContextStage receives ctx.
+func execute[D any](ctx context.Context, in In, done <-chan D, stages []PipelineStage) (Out, *execution) {
+	e := &execution{finished: make(chan struct{})}
+	if in == nil || len(stages) == 0 || len(stages) == 1 && isNilStage(stages[0]) {
 		outputChan := make(Bi)
 		close(outputChan)
-		return outputChan
+		close(e.finished)
+		return outputChan, e
 	}
 
+	hooks := wrapperHooks{
+		stopped: func() { e.stopped.Store(true) },
+	}
 	currentChan := in
 	for _, stage := range stages {
-		if stage == nil {
+		if isNilStage(stage) {
 			continue
 		}
-		currentChan = stage(chanWrapper(currentChan, done))
+		currentChan = stage.start(ctx, hookedChanWrapper(currentChan, done, hooks))
 	}
 
-	return chanWrapper(currentChan, done)
+	hooks.finished = e.finished
+	return hookedChanWrapper(currentChan, done, hooks), e
+}
+
+func isNilStage(stage PipelineStage) bool {
+	return stage == nil || stage.isNil()
 }
 
 // chanWrapper transfer data from input chan to out chan, that will be closed when get done signal.
 // When done signal will be received, the func drain data from input channel to avoid block producer.
-func chanWrapper(in In, done In) Out {
-	out := make(Bi)
+func chanWrapper[T, D any](in <-chan T, done <-chan D) <-chan T {
+	return hookedChanWrapper(in, done, wrapperHooks{})
+}
+
+// wrapperHooks are optional parameters of hookedChanWrapper.
+type wrapperHooks struct {
+	// buffer is the capacity of the output channel.
+	buffer int
+	// finished is closed after the input channel is drained.
+	finished chan<- struct{}
+	// stopped is called when the wrapper stops on done before the input is closed.
+	stopped func()
+	// received and sent are called with the time spent waiting for a value and for its consumer.
+	received, sent func(blocked time.Duration)
+}
+
+// hookedChanWrapper works like chanWrapper and calls the hooks that are not nil.
+func hookedChanWrapper[T, D any](in <-chan T, done <-chan D, hooks wrapperHooks) <-chan T {
+	out := make(chan T, hooks.buffer)
+	timed := hooks.received != nil || hooks.sent != nil
 
 	go func() {
+		// closed is set when the input is closed, otherwise the wrapper stopped on done
+		closed := false
 		defer func() {
+			if !closed && hooks.stopped != nil {
+				hooks.stopped()
+			}
 			close(out)
 
 			// drainage input channel to release resources
 			//nolint:revive
 			for range in {
 			}
+			if hooks.finished != nil {
+				close(hooks.finished)
+			}
 		}()
 
+		var start time.Time
 		for {
+			// select chooses randomly between ready cases, so done is checked first
+			if isDone(done) {
+				return
+			}
+			if timed {
+				start = time.Now()
+			}
 			select {
 			case <-done:
 				return
 			case val, ok := <-in:
 				if !ok {
+					closed = true
 					return
 				}
+				if isDone(done) {
+					return
+				}
+				if hooks.received != nil {
+					hooks.received(time.Since(start))
+				}
+
+				if timed {
+					start = time.Now()
+				}
 				select {
 				case out <- val:
+					if hooks.sent != nil {
+						hooks.sent(time.Since(start))
+					}
 				case <-done:
 					return
 				}
@@ -60,3 +146,13 @@ func chanWrapper(in In, done In) Out {
 
 	return out
 }
+
+// isDone reports whether done is closed without blocking.
+func isDone[D any](done <-chan D) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
@@ -139,6 +139,21 @@ func TestPipelineCornerCases(t *testing.T) {
 		require.Len(t, result, 0)
 	})
 
+	t.Run("done channel is closed before start", func(t *testing.T) {
+		done := make(Bi)
+		close(done)
+		id := Stage(func(in In) Out { return in })
+
+		// the producer ignores done and is released by draining
+		for range 100 {
+			require.Empty(t, collect(ExecutePipeline(generate[interface{}](1, 2, 3), done, id, id)))
+		}
+	})
+
+	t.Run("all stages are nil", func(t *testing.T) {
+		require.Equal(t, []interface{}{1, 2, 3}, collect(ExecutePipeline(generate[interface{}](1, 2, 3), nil, nil, nil)))
+	})
+
 	t.Run("nil stages", func(t *testing.T) {
 		in := make(Bi)
 		data := []int{1, 2, 3, 4, 5}
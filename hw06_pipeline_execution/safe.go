@@ -0,0 +1,72 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrStagePanicked is wrapped by PanicError.
+var ErrStagePanicked = errors.New("stage panicked")
+
+// PanicError is an error of a value processing that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrStagePanicked, e.Value)
+}
+
+// Unwrap allows to match ErrStagePanicked and the error passed to panic.
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrStagePanicked, err}
+	}
+
+	return []error{ErrStagePanicked}
+}
+
+// SafeStage returns a stage applying fn to every value, recovering from panics of fn.
+// A value failed with an error or a panic is sent as a StageError with the stage index
+// to deadLetters and processing continues with the next value.
+// Failed values are dropped if deadLetters is nil, otherwise it must be read until done is closed
+// or the stage output is closed.
+func SafeStage(done In, stage int, fn func(v interface{}) (interface{}, error), deadLetters chan<- *StageError) Stage {
+	return func(in In) Out {
+		out := make(Bi)
+		go func() {
+			defer close(out)
+			for v := range in {
+				res, err := safeCall(fn, v)
+				if err != nil {
+					if deadLetters == nil {
+						continue
+					}
+					select {
+					case <-done:
+						return
+					case deadLetters <- &StageError{Stage: stage, Value: v, Err: err}:
+					}
+					continue
+				}
+
+				if !send(done, out, res) {
+					return
+				}
+			}
+		}()
+		return out
+	}
+}
+
+func safeCall(fn func(v interface{}) (interface{}, error), v interface{}) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn(v)
+}
@@ -0,0 +1,83 @@
+package hw06pipelineexecution
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestSafeStage(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	errOdd := errors.New("odd value")
+	fn := func(v interface{}) (interface{}, error) {
+		switch {
+		case v.(int) == 0:
+			panic("zero value")
+		case v.(int)%2 != 0:
+			return nil, errOdd
+		}
+		return v.(int) * 10, nil
+	}
+
+	t.Run("failed values go to dead letters", func(t *testing.T) {
+		deadLetters := make(chan *StageError)
+		var failed []*StageError
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range deadLetters {
+				failed = append(failed, e)
+			}
+		}()
+
+		result := collect(ExecutePipeline(generate[interface{}](2, 0, 3, 4), nil, SafeStage(nil, 1, fn, deadLetters)))
+		close(deadLetters)
+		wg.Wait()
+
+		require.Equal(t, []interface{}{20, 40}, result)
+		require.Len(t, failed, 2)
+
+		require.Equal(t, 1, failed[0].Stage)
+		require.Equal(t, 0, failed[0].Value)
+		require.ErrorIs(t, failed[0], ErrStagePanicked)
+		var panicErr *PanicError
+		require.ErrorAs(t, failed[0], &panicErr)
+		require.Equal(t, "zero value", panicErr.Value)
+		require.NotEmpty(t, panicErr.Stack)
+
+		require.Equal(t, 3, failed[1].Value)
+		require.ErrorIs(t, failed[1], errOdd)
+	})
+
+	t.Run("nil dead letters", func(t *testing.T) {
+		result := collect(ExecutePipeline(generate[interface{}](0, 1, 2), nil, SafeStage(nil, 0, fn, nil)))
+		require.Equal(t, []interface{}{20}, result)
+	})
+
+	t.Run("panic with error", func(t *testing.T) {
+		errPanic := errors.New("panic error")
+		deadLetters := make(chan *StageError, 1)
+		panicking := func(interface{}) (interface{}, error) {
+			panic(errPanic)
+		}
+
+		require.Empty(t, collect(ExecutePipeline(generate[interface{}](1), nil, SafeStage(nil, 0, panicking, deadLetters))))
+		failed := <-deadLetters
+		require.ErrorIs(t, failed, ErrStagePanicked)
+		require.ErrorIs(t, failed, errPanic)
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		done := make(Bi)
+		close(done)
+
+		// nobody reads dead letters, the stage must not block after done
+		deadLetters := make(chan *StageError)
+		require.Empty(t, collect(ExecutePipeline(generate[interface{}](1, 3, 5), done, SafeStage(done, 0, fn, deadLetters))))
+	})
+}
@@ -0,0 +1,64 @@
+package hw06pipelineexecution
+
+// TypedStage is a Stage consuming values of type I and producing values of type O.
+type TypedStage[I, O any] func(in <-chan I) (out <-chan O)
+
+// Pipeline is a type-safe chain of stages transforming values of type I into values of type O.
+// It is built with Pipe and Then and started with Execute.
+type Pipeline[I, O any] struct {
+	run func(in <-chan I, done In) <-chan O
+}
+
+// Pipe starts a pipeline with the stage.
+func Pipe[I, O any](stage TypedStage[I, O]) Pipeline[I, O] {
+	return Pipeline[I, O]{
+		run: func(in <-chan I, done In) <-chan O {
+			return stage(chanWrapper(in, done))
+		},
+	}
+}
+
+// Then appends the stage to the pipeline, it consumes values produced by the previous stage.
+func Then[I, M, O any](p Pipeline[I, M], stage TypedStage[M, O]) Pipeline[I, O] {
+	return Pipeline[I, O]{
+		run: func(in <-chan I, done In) <-chan O {
+			return stage(chanWrapper(p.run(in, done), done))
+		},
+	}
+}
+
+// Execute runs the pipeline like ExecutePipeline does: the output channel is closed
+// when the input is exhausted or done is closed, and unread values are drained.
+func (p Pipeline[I, O]) Execute(in <-chan I, done In) <-chan O {
+	if in == nil || p.run == nil {
+		out := make(chan O)
+		close(out)
+		return out
+	}
+
+	return chanWrapper(p.run(in, done), done)
+}
+
+// ExecuteTyped runs stages that keep the type of values, nil stages are skipped
+// like ExecutePipeline does.
+func ExecuteTyped[T any](in <-chan T, done In, stages ...TypedStage[T, T]) <-chan T {
+	if in == nil || len(stages) == 0 || len(stages) == 1 && stages[0] == nil {
+		out := make(chan T)
+		close(out)
+		return out
+	}
+
+	p := Pipeline[T, T]{
+		run: func(in <-chan T, _ In) <-chan T {
+			return in
+		},
+	}
+	for _, stage := range stages {
+		if stage == nil {
+			continue
+		}
+		p = Then(p, stage)
+	}
+
+	return p.Execute(in, done)
+}
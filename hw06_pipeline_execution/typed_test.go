@@ -0,0 +1,130 @@
+package hw06pipelineexecution
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// typedStage returns a stage applying f to every value with sleepPerStage delay.
+func typedStage[I, O any](wg *sync.WaitGroup, f func(I) O) TypedStage[I, O] {
+	return func(in <-chan I) <-chan O {
+		out := make(chan O)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(out)
+			for v := range in {
+				time.Sleep(sleepPerStage)
+				out <- f(v)
+			}
+		}()
+		return out
+	}
+}
+
+func generate[T any](data ...T) <-chan T {
+	in := make(chan T)
+	go func() {
+		defer close(in)
+		for _, v := range data {
+			in <- v
+		}
+	}()
+	return in
+}
+
+func TestTypedPipeline(t *testing.T) {
+	t.Run("simple case", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		p := Then(
+			Then(
+				Pipe(typedStage(wg, func(v int) int { return v * 2 })),
+				typedStage(wg, func(v int) int { return v + 100 }),
+			),
+			typedStage(wg, strconv.Itoa),
+		)
+
+		result := make([]string, 0, 5)
+		start := time.Now()
+		for s := range p.Execute(generate(1, 2, 3, 4, 5), nil) {
+			result = append(result, s)
+		}
+		elapsed := time.Since(start)
+		wg.Wait()
+
+		require.Equal(t, []string{"102", "104", "106", "108", "110"}, result)
+		// ~0.7s for processing 5 values in 3 stages (100ms every) concurrently
+		require.Less(t, int64(elapsed), int64(sleepPerStage)*int64(3+5-1)+int64(fault))
+	})
+
+	t.Run("done case", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		p := Then(
+			Pipe(typedStage(wg, func(v int) int { return v * 2 })),
+			typedStage(wg, strconv.Itoa),
+		)
+
+		// Abort before the first value passes both stages
+		done := make(Bi)
+		abortDur := sleepPerStage
+		go func() {
+			<-time.After(abortDur)
+			close(done)
+		}()
+
+		result := make([]string, 0, 5)
+		start := time.Now()
+		for s := range p.Execute(generate(1, 2, 3, 4, 5), done) {
+			result = append(result, s)
+		}
+		elapsed := time.Since(start)
+		wg.Wait()
+
+		require.Len(t, result, 0)
+		require.Less(t, int64(elapsed), int64(abortDur)+int64(fault))
+	})
+
+	t.Run("same type stages", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		out := ExecuteTyped(generate(1, 2, 3), nil,
+			typedStage(wg, func(v int) int { return v + 1 }),
+			nil,
+			typedStage(wg, func(v int) int { return v * v }),
+		)
+
+		result := make([]int, 0, 3)
+		for v := range out {
+			result = append(result, v)
+		}
+		wg.Wait()
+
+		require.Equal(t, []int{4, 9, 16}, result)
+	})
+
+	t.Run("nil input and empty pipeline", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		p := Pipe(typedStage(wg, strconv.Itoa))
+		for range p.Execute(nil, nil) {
+			require.Fail(t, "unexpected value")
+		}
+
+		for range ExecuteTyped(make(<-chan int), nil) {
+			require.Fail(t, "unexpected value")
+		}
+		for range ExecuteTyped(make(<-chan int), nil, nil) {
+			require.Fail(t, "unexpected value")
+		}
+	})
+
+	t.Run("all stages are nil", func(t *testing.T) {
+		result := make([]int, 0, 3)
+		for v := range ExecuteTyped(generate(1, 2, 3), nil, nil, nil) {
+			result = append(result, v)
+		}
+		require.Equal(t, []int{1, 2, 3}, result)
+	})
+}
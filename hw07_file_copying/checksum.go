@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+// ChecksumSuffix is the suffix of the checksum file name added to the destination path.
+const ChecksumSuffix = ".sha256"
+
+var (
+	ErrUnknownHash      = errors.New("unknown hash algorithm")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+var hashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"md5":    md5.New,
+	"crc32c": func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"xxhash": func() hash.Hash { return newXXHash64() },
+}
+
+// HashAlgorithms returns the names of supported hash algorithms.
+func HashAlgorithms() []string {
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// checksummer hashes the copied data to verify the destination and write the checksum file.
+type checksummer struct {
+	algorithm string
+	// verify is nil if the destination is not verified.
+	verify hash.Hash
+	// sha is nil if the checksum file is not written.
+	sha hash.Hash
+}
+
+func newChecksummer(o *options) (*checksummer, error) {
+	if o.verify == "" && !o.checksumFile {
+		return nil, nil
+	}
+
+	c := &checksummer{algorithm: o.verify}
+	if o.verify != "" {
+		newHash, ok := hashes[o.verify]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownHash, o.verify)
+		}
+		c.verify = newHash()
+	}
+	if o.checksumFile {
+		c.sha = sha256.New()
+	}
+
+	return c, nil
+}
+
+// Write adds the copied data to the hashes.
+func (c *checksummer) Write(p []byte) (int, error) {
+	if c.verify != nil {
+		c.verify.Write(p)
+	}
+	if c.sha != nil {
+		c.sha.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// check re-reads size bytes of the destination and compares its checksum with the copied data one.
+func (c *checksummer) check(dest io.ReaderAt, size int64) error {
+	if c == nil || c.verify == nil {
+		return nil
+	}
+
+	h := hashes[c.algorithm]()
+	if _, err := io.Copy(h, io.NewSectionReader(dest, 0, size)); err != nil {
+		return fmt.Errorf("read destination file: %w", err)
+	}
+
+	want, got := c.verify.Sum(nil), h.Sum(nil)
+	if string(want) != string(got) {
+		return fmt.Errorf("%w: %s source %x, destination %x", ErrChecksumMismatch, c.algorithm, want, got)
+	}
+
+	return nil
+}
+
+// writeChecksumFile writes the SHA-256 of the copied data in the sha256sum format.
+func (c *checksummer) writeChecksumFile(destPath string) error {
+	if c == nil || c.sha == nil {
+		return nil
+	}
+
+	line := hex.EncodeToString(c.sha.Sum(nil)) + "  " + filepath.Base(destPath) + "\n"
+	if err := writeFileAtomic(destPath+ChecksumSuffix, []byte(line)); err != nil {
+		return fmt.Errorf("write checksum file: %w", err)
+	}
+
+	return nil
+}
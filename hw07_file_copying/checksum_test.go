@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXXHash64(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+	}{
+		{input: "", expected: 0xef46db3751d8e999},
+		{input: "a", expected: 0xd24ec4f1a98c6e5b},
+		{input: "abc", expected: 0x44bc2cf5ad770999},
+		{input: "Nobody inspects the spammish repetition", expected: 0xfbcea83c8a378bf1},
+		{input: "0123456789abcdefghijklmnopqrstuv", expected: 0xbf7c9dbe16b5c6e2},
+		{input: strings.Repeat("0123456789abcdefghijklmnopqrstuv", 2), expected: 0xf97de1e3f512efa0},
+		{input: strings.Repeat("0123456789", 10), expected: 0xf80e7b96315afffa},
+		{input: strings.Repeat("The quick brown fox jumps over the lazy dog. ", 22), expected: 0x7ed4b6fb9449de81},
+	}
+
+	for _, tc := range tests {
+		t.Run(strconv.Itoa(len(tc.input)), func(t *testing.T) {
+			h := newXXHash64()
+			h.Write([]byte(tc.input))
+			require.Equal(t, tc.expected, h.Sum64())
+
+			// the result does not depend on write sizes, the chunks cross 32-byte stripe boundaries
+			for _, size := range []int{1, 7, 31, 33} {
+				h.Reset()
+				for i := 0; i < len(tc.input); i += size {
+					h.Write([]byte(tc.input[i:min(i+size, len(tc.input))]))
+				}
+				require.Equal(t, tc.expected, h.Sum64(), size)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	input := bytes.Repeat([]byte("0123456789"), 1000)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+	outPath := filepath.Join(testDir, "out.txt")
+
+	for _, algorithm := range HashAlgorithms() {
+		t.Run(algorithm, func(t *testing.T) {
+			require.NoError(t, Copy(inputPath, outPath, 10, 5000, WithVerify(algorithm)))
+
+			content, err := os.ReadFile(outPath)
+			require.NoError(t, err)
+			require.Equal(t, input[10:5010], content)
+		})
+	}
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		err := Copy(inputPath, outPath, 0, 0, WithVerify("sha3"))
+		require.ErrorIs(t, err, ErrUnknownHash)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		sum, err := newChecksummer(&options{verify: "crc32c"})
+		require.NoError(t, err)
+		sum.Write(input)
+
+		corrupted := append([]byte(nil), input...)
+		corrupted[42] = 'x'
+		require.NoError(t, sum.check(bytes.NewReader(input), int64(len(input))))
+		require.ErrorIs(t, sum.check(bytes.NewReader(corrupted), int64(len(corrupted))), ErrChecksumMismatch)
+	})
+
+	t.Run("checksum file", func(t *testing.T) {
+		require.NoError(t, Copy(inputPath, outPath, 100, 0, WithChecksumFile()))
+
+		expected := sha256.Sum256(input[100:])
+		content, err := os.ReadFile(outPath + ChecksumSuffix)
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(expected[:])+"  out.txt\n", string(content))
+	})
+
+	t.Run("resumed copy", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(outPath, input[:4000], 0o644))
+		require.NoError(t, Copy(inputPath, outPath, 0, 0, WithResume(), WithVerify("sha256"), WithChecksumFile()))
+
+		expected := sha256.Sum256(input)
+		content, err := os.ReadFile(outPath + ChecksumSuffix)
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(expected[:])+"  out.txt\n", string(content))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		setStdin(t, "123456")
+		require.NoError(t, Copy(StdinPath, outPath, 1, 0, WithVerify("xxhash")))
+	})
+}
@@ -5,66 +5,253 @@ import (
 	"fmt"
 	"io"
 	"os"
-
-	"github.com/cheggaaa/pb/v3"
 )
 
+// StdinPath is the source path meaning the standard input.
+const StdinPath = "-"
+
 var (
 	ErrUnsupportedFile       = errors.New("unsupported file")
 	ErrOffsetExceedsFileSize = errors.New("offset exceeds file size")
 )
 
-func Copy(fromPath, toPath string, offset, limit int64) (err error) {
-	srcFile, err := os.Open(fromPath)
+// stdin is the source file for StdinPath, it is replaced in tests.
+var stdin = os.Stdin
+
+func Copy(fromPath, toPath string, offset, limit int64, opts ...Option) (err error) {
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	srcFile, err := openSource(fromPath)
 	if err != nil {
 		return fmt.Errorf("open source file: %w", err)
 	}
-	defer func() {
-		if closeErr := srcFile.Close(); closeErr != nil {
-			err = errorJoin(err, fmt.Errorf("close source file: %w", closeErr))
-		}
-	}()
+	if srcFile != stdin {
+		defer func() {
+			if closeErr := srcFile.Close(); closeErr != nil {
+				err = errorJoin(err, fmt.Errorf("close source file: %w", closeErr))
+			}
+		}()
+	}
+
+	return copyFile(srcFile, toPath, offset, limit, o)
+}
 
+// copyFile copies the opened source file, the caller closes it.
+func copyFile(srcFile *os.File, toPath string, offset, limit int64, o *options) error {
 	srcInfo, err := srcFile.Stat()
 	if err != nil {
 		return fmt.Errorf("get file info: %w", err)
 	}
 
+	var src io.Reader
+	switch {
 	// For regular files, the expression mode & os.ModeType will return 0
-	if srcInfo.Size() < 0 || srcInfo.Mode()&os.ModeType != 0 {
+	case srcInfo.Mode()&os.ModeType == 0 && srcInfo.Size() >= 0:
+		fileSize := srcInfo.Size()
+		if offset > fileSize {
+			return fmt.Errorf("%w: offset %d exceeds file size %d",
+				ErrOffsetExceedsFileSize, offset, fileSize)
+		}
+
+		if limit == 0 || offset+limit > fileSize {
+			limit = fileSize - offset
+		}
+		src = newSectionReader(srcFile, offset, offset+limit, o.sparse)
+	case isStream(srcInfo.Mode()):
+		if o.resume {
+			return ErrResumeUnsupported
+		}
+		if err := skip(srcFile, offset); err != nil {
+			return err
+		}
+		src = srcFile
+	default:
 		return ErrUnsupportedFile
 	}
-	fileSize := srcInfo.Size()
 
-	if offset > fileSize {
-		return fmt.Errorf("%w: offset %d exceeds file size %d",
-			ErrOffsetExceedsFileSize, offset, fileSize)
+	sum, err := newChecksummer(o)
+	if err != nil {
+		return err
 	}
 
-	if limit == 0 || offset+limit > fileSize {
-		limit = fileSize - offset
+	dest, err := createDestination(toPath, srcInfo, o)
+	if err != nil {
+		return err
 	}
 
-	destFile, err := os.Create(toPath)
-	if err != nil {
-		return fmt.Errorf("create destination file: %w", err)
+	job := &copyJob{
+		src:     src,
+		srcFile: srcFile,
+		regular: !isStream(srcInfo.Mode()),
+		dest:    dest.File,
+		offset:  offset,
+		limit:   limit,
+		sum:     sum,
+		o:       o,
+	}
+	if err := job.run(); err != nil {
+		return errorJoin(err, dest.abort())
+	}
+	if err := dest.commit(); err != nil {
+		return err
+	}
+
+	return sum.writeChecksumFile(toPath)
+}
+
+// copyJob copies the source data range to the destination file.
+type copyJob struct {
+	src io.Reader
+	// srcFile is the source file src reads from offset, it supports ReadAt if regular is set.
+	srcFile *os.File
+	regular bool
+	dest    *os.File
+	offset  int64
+	// limit is the number of bytes to copy, all the data until EOF is copied if it is zero.
+	limit int64
+	sum   *checksummer
+	o     *options
+}
+
+func (j *copyJob) run() (err error) {
+	var (
+		src               = j.src
+		w       io.Writer = j.dest
+		resumed int64
+		cp      *checkpointer
+	)
+	if j.o.resume {
+		if cp, err = newCheckpointer(j.srcFile, j.dest, j.offset, j.limit); err != nil {
+			return err
+		}
+		if resumed, err = cp.prepare(j.srcFile); err != nil {
+			return err
+		}
+		src = newSectionReader(j.srcFile, j.offset+resumed, j.offset+j.limit, j.o.sparse)
+	}
+	if j.o.sparse {
+		w = newSparseWriter(j.dest, resumed)
+	}
+	if cp != nil {
+		cp.w = w
+		w = cp
+	}
+	if j.sum != nil {
+		// the resumed prefix is verified only by its tail, so the whole prefix is hashed
+		if _, err := io.Copy(j.sum, io.NewSectionReader(j.srcFile, j.offset, resumed)); err != nil {
+			return fmt.Errorf("read source file: %w", err)
+		}
+		src = io.TeeReader(src, j.sum)
+	}
+
+	progress := j.o.progress
+	if !j.o.progressStarted {
+		progress.Start(j.limit)
+		defer progress.Finish()
+	}
+	progress.Add(resumed)
+
+	if j.parallelAllowed() {
+		return j.runParallel(progress.Add)
 	}
-	defer func() {
-		if closeErr := destFile.Close(); closeErr != nil {
-			err = errorJoin(err, fmt.Errorf("close destination file: %w", closeErr))
+
+	if j.zeroCopyAllowed() {
+		if _, err := zeroCopy(j.dest, j.srcFile, j.offset, j.limit, progress.Add); err != nil {
+			return fmt.Errorf("copy data: %w", err)
 		}
-	}()
+		return nil
+	}
 
-	bar := pb.Full.Start64(limit)
-	bar.Set(pb.Bytes, true)
-	defer bar.Finish()
+	proxyReader := &progressReader{r: j.o.limiter.reader(src), progress: progress}
 
-	sr := io.NewSectionReader(srcFile, offset, limit)
-	proxyReader := bar.NewProxyReader(sr)
+	var written int64
+	if j.limit == 0 {
+		written, err = io.Copy(w, proxyReader)
+	} else {
+		written, err = io.CopyN(w, proxyReader, j.limit-resumed)
+	}
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	if err == nil {
+		err = flushWriter(w)
+	}
+	if err != nil {
+		err = fmt.Errorf("copy data: %w", err)
+	}
 
-	if _, err := io.CopyN(destFile, proxyReader, limit); err != nil && !errors.Is(err, io.EOF) {
+	if cp != nil {
+		err = cp.finish(err)
+	}
+	if err != nil {
+		return err
+	}
+
+	return j.sum.check(j.dest, resumed+written)
+}
+
+// parallelAllowed reports whether the data may be copied by parallel workers.
+func (j *copyJob) parallelAllowed() bool {
+	return j.regular && j.limit > 0 && j.o.parallel > 1
+}
+
+func (j *copyJob) runParallel(progress func(int64)) error {
+	c := &chunkCopier{
+		dest:     j.dest,
+		src:      j.srcFile,
+		off:      j.offset,
+		sparse:   j.o.sparse,
+		limiter:  j.o.limiter,
+		progress: progress,
+	}
+	if err := c.run(j.limit, j.o.parallel); err != nil {
 		return fmt.Errorf("copy data: %w", err)
 	}
+	if j.sum == nil {
+		return nil
+	}
+
+	// chunks are copied in any order, so the source is hashed separately
+	if _, err := io.Copy(j.sum, io.NewSectionReader(j.srcFile, j.offset, j.limit)); err != nil {
+		return fmt.Errorf("read source file: %w", err)
+	}
+
+	return j.sum.check(j.dest, j.limit)
+}
+
+// zeroCopyAllowed reports whether the data may be copied by the kernel without passing through
+// the process, it is not possible when the data is hashed, checked for zero blocks, checkpointed or throttled.
+func (j *copyJob) zeroCopyAllowed() bool {
+	return j.regular && j.limit > 0 && j.sum == nil && j.o.limiter == nil &&
+		!j.o.sparse && !j.o.resume && !j.o.noZeroCopy
+}
+
+func openSource(path string) (*os.File, error) {
+	if path == StdinPath {
+		return stdin, nil
+	}
+
+	return os.Open(path)
+}
+
+// isStream reports whether the file is read sequentially and its size is unknown:
+// a named pipe, a socket or a character device.
+func isStream(mode os.FileMode) bool {
+	return mode&(os.ModeNamedPipe|os.ModeSocket|os.ModeCharDevice) != 0
+}
+
+// skip discards offset bytes of the stream.
+func skip(r io.Reader, offset int64) error {
+	n, err := io.CopyN(io.Discard, r, offset)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: offset %d exceeds stream size %d", ErrOffsetExceedsFileSize, offset, n)
+	}
+	if err != nil {
+		return fmt.Errorf("skip offset: %w", err)
+	}
 
 	return nil
 }
@@ -118,4 +118,73 @@ func TestCopy(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("stream sources", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			input    string
+			offset   int64
+			limit    int64
+			expected string
+		}{
+			{name: "entire stream", input: "123456", expected: "123456"},
+			{name: "stream with offset", input: "123456", offset: 2, expected: "3456"},
+			{name: "stream with offset and limit", input: "123456", offset: 1, limit: 3, expected: "234"},
+			{name: "limit exceeds stream size", input: "123456", limit: 100, expected: "123456"},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				setStdin(t, tc.input)
+
+				outPath := filepath.Join(testDir, "out.txt")
+				err := Copy(StdinPath, outPath, tc.offset, tc.limit)
+				require.NoError(t, err)
+
+				content, err := os.ReadFile(outPath)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, string(content))
+			})
+		}
+
+		t.Run("offset exceeds stream size", func(t *testing.T) {
+			setStdin(t, "123456")
+
+			err := Copy(StdinPath, filepath.Join(testDir, "out.txt"), 10, 0)
+			require.ErrorIs(t, err, ErrOffsetExceedsFileSize)
+		})
+
+		t.Run("character device with limit", func(t *testing.T) {
+			if _, err := os.Stat("/dev/zero"); err != nil {
+				t.Skip("/dev/zero is not available")
+			}
+
+			outPath := filepath.Join(testDir, "out.txt")
+			err := Copy("/dev/zero", outPath, 10, 1024)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(outPath)
+			require.NoError(t, err)
+			require.Equal(t, make([]byte, 1024), content)
+		})
+	})
+}
+
+// setStdin replaces the standard input source with a pipe containing the input.
+func setStdin(t *testing.T, input string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	go func() {
+		_, _ = w.WriteString(input)
+		_ = w.Close()
+	}()
+
+	prev := stdin
+	stdin = r
+	t.Cleanup(func() {
+		stdin = prev
+		_ = r.Close()
+	})
 }
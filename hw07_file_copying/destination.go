@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultPerm is the permissions of a new destination when the source is not a regular file.
+const defaultPerm os.FileMode = 0o644
+
+var ErrSameFile = errors.New("source and destination are the same file")
+
+// destination is the file the data is copied to. In atomic mode it is a temporary file
+// in the destination directory that replaces the destination on commit.
+type destination struct {
+	*os.File
+	path   string
+	atomic bool
+	perm   os.FileMode
+}
+
+func createDestination(path string, srcInfo os.FileInfo, o *options) (*destination, error) {
+	d := &destination{path: path, atomic: o.atomic, perm: o.perm}
+
+	destInfo, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if os.SameFile(srcInfo, destInfo) {
+			return nil, ErrSameFile
+		}
+		if d.atomic {
+			// the link target is replaced, like the data is written through the link in non-atomic mode
+			if d.path, err = filepath.EvalSymlinks(path); err != nil {
+				return nil, fmt.Errorf("resolve destination path: %w", err)
+			}
+			if d.perm == 0 {
+				d.perm = destInfo.Mode().Perm()
+			}
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if d.perm == 0 && d.atomic {
+			d.perm = defaultPerm
+			if srcInfo.Mode().IsRegular() {
+				d.perm = srcInfo.Mode().Perm()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("get destination file info: %w", err)
+	}
+
+	switch {
+	case d.atomic:
+		d.File, err = os.CreateTemp(filepath.Dir(d.path), "."+filepath.Base(d.path)+".*.tmp")
+	case o.resume:
+		d.File, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o666)
+	default:
+		d.File, err = os.Create(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create destination file: %w", err)
+	}
+
+	return d, nil
+}
+
+// commit closes the destination, in atomic mode the temporary file is synced and renamed to the destination.
+func (d *destination) commit() error {
+	if d.perm != 0 {
+		if err := d.Chmod(d.perm); err != nil {
+			return errorJoin(fmt.Errorf("set destination permissions: %w", err), d.abort())
+		}
+	}
+
+	if !d.atomic {
+		if err := d.Close(); err != nil {
+			return fmt.Errorf("close destination file: %w", err)
+		}
+		return nil
+	}
+
+	if err := d.Sync(); err != nil {
+		return errorJoin(fmt.Errorf("sync destination file: %w", err), d.abort())
+	}
+	if err := d.Close(); err != nil {
+		return errorJoin(fmt.Errorf("close destination file: %w", err), d.abort())
+	}
+	if err := os.Rename(d.Name(), d.path); err != nil {
+		return errorJoin(fmt.Errorf("rename destination file: %w", err), d.abort())
+	}
+
+	return syncDir(filepath.Dir(d.path))
+}
+
+// abort closes the destination, in atomic mode the temporary file is removed.
+func (d *destination) abort() error {
+	err := d.Close()
+	if errors.Is(err, os.ErrClosed) {
+		err = nil
+	}
+	if err != nil {
+		err = fmt.Errorf("close destination file: %w", err)
+	}
+
+	if d.atomic {
+		if rmErr := os.Remove(d.Name()); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+			err = errorJoin(err, fmt.Errorf("remove temporary file: %w", rmErr))
+		}
+	}
+
+	return err
+}
+
+// syncDir flushes the directory entries, so a renamed file survives a crash.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open destination directory: %w", err)
+	}
+
+	if err := dir.Sync(); err != nil {
+		return errorJoin(fmt.Errorf("sync destination directory: %w", err), dir.Close())
+	}
+
+	return dir.Close()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicCopy(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	require.NoError(t, os.WriteFile(inputPath, []byte("123456"), 0o600))
+	require.NoError(t, os.Chmod(inputPath, 0o600))
+
+	// tempFiles returns names of temporary files left in the test directory
+	tempFiles := func(t *testing.T) []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(testDir, ".*.tmp"))
+		require.NoError(t, err)
+		return matches
+	}
+
+	t.Run("new destination gets source permissions", func(t *testing.T) {
+		outPath := filepath.Join(testDir, "new.txt")
+		require.NoError(t, Copy(inputPath, outPath, 1, 3, WithAtomic()))
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, "234", string(content))
+
+		info, err := os.Stat(outPath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		require.Empty(t, tempFiles(t))
+	})
+
+	t.Run("existing destination permissions are preserved", func(t *testing.T) {
+		outPath := filepath.Join(testDir, "existing.txt")
+		require.NoError(t, os.WriteFile(outPath, []byte("old content"), 0o640))
+		require.NoError(t, os.Chmod(outPath, 0o640))
+
+		require.NoError(t, Copy(inputPath, outPath, 0, 0, WithAtomic()))
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, "123456", string(content))
+
+		info, err := os.Stat(outPath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	})
+
+	t.Run("permissions are set", func(t *testing.T) {
+		for _, opts := range [][]Option{{WithAtomic()}, nil} {
+			outPath := filepath.Join(testDir, "perm.txt")
+			require.NoError(t, Copy(inputPath, outPath, 0, 0, append(opts, WithPermissions(0o604))...))
+
+			info, err := os.Stat(outPath)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o604), info.Mode().Perm())
+			require.NoError(t, os.Remove(outPath))
+		}
+	})
+
+	t.Run("abort keeps destination", func(t *testing.T) {
+		outPath := filepath.Join(testDir, "abort.txt")
+		require.NoError(t, os.WriteFile(outPath, []byte("old content"), 0o644))
+
+		srcInfo, err := os.Stat(inputPath)
+		require.NoError(t, err)
+		dest, err := createDestination(outPath, srcInfo, &options{atomic: true})
+		require.NoError(t, err)
+		_, err = dest.WriteString("partial")
+		require.NoError(t, err)
+		require.NoError(t, dest.abort())
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, "old content", string(content))
+		require.Empty(t, tempFiles(t))
+	})
+
+	t.Run("same file", func(t *testing.T) {
+		linkPath := filepath.Join(testDir, "link.txt")
+		require.NoError(t, os.Link(inputPath, linkPath))
+
+		for _, outPath := range []string{inputPath, linkPath} {
+			require.ErrorIs(t, Copy(inputPath, outPath, 0, 0), ErrSameFile)
+			require.ErrorIs(t, Copy(inputPath, outPath, 0, 0, WithAtomic()), ErrSameFile)
+		}
+
+		content, err := os.ReadFile(inputPath)
+		require.NoError(t, err)
+		require.Equal(t, "123456", string(content))
+	})
+
+	t.Run("symbolic link destination", func(t *testing.T) {
+		targetDir := filepath.Join(testDir, "target")
+		require.NoError(t, os.Mkdir(targetDir, 0o755))
+		targetPath := filepath.Join(targetDir, "target.txt")
+		linkPath := filepath.Join(testDir, "link-dest.txt")
+		require.NoError(t, os.Symlink(targetPath, linkPath))
+
+		for _, opts := range [][]Option{{WithAtomic()}, nil} {
+			require.NoError(t, os.WriteFile(targetPath, []byte("old content"), 0o644))
+			require.NoError(t, Copy(inputPath, linkPath, 0, 0, opts...))
+
+			content, err := os.ReadFile(targetPath)
+			require.NoError(t, err)
+			require.Equal(t, "123456", string(content))
+
+			info, err := os.Lstat(linkPath)
+			require.NoError(t, err)
+			require.Equal(t, os.ModeSymlink, info.Mode().Type())
+		}
+		require.Empty(t, tempFiles(t))
+	})
+
+	t.Run("atomic resume", func(t *testing.T) {
+		err := Copy(inputPath, filepath.Join(testDir, "out.txt"), 0, 0, WithAtomic(), WithResume())
+		require.ErrorIs(t, err, ErrInvalidOptions)
+	})
+}
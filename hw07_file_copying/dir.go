@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy defines how CopyDir copies symbolic links.
+type SymlinkPolicy int
+
+const (
+	// SymlinksCopy creates the same symbolic links in the destination.
+	SymlinksCopy SymlinkPolicy = iota
+	// SymlinksFollow copies files and directories the symbolic links point to.
+	SymlinksFollow
+)
+
+var (
+	ErrNotDirectory            = errors.New("source is not a directory")
+	ErrDestinationInsideSource = errors.New("destination is inside the source directory")
+	ErrSymlinkLoop             = errors.New("symbolic link loop")
+)
+
+// dirEntry is a file, a directory or a symbolic link copied by CopyDir.
+type dirEntry struct {
+	src  string
+	dest string
+	// info is the entry info, it is the info of the link target if the link is followed.
+	info os.FileInfo
+	// link is the target of the copied symbolic link.
+	link string
+}
+
+// CopyDir copies the directory tree to toPath preserving mode bits and modification and access times,
+// toPath becomes the copy of fromPath. Files are copied like Copy does with the same options,
+// the progress reports the total size of all files.
+func CopyDir(fromPath, toPath string, opts ...Option) error {
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(fromPath)
+	if err != nil {
+		return fmt.Errorf("get source directory info: %w", err)
+	}
+	if !info.IsDir() {
+		return ErrNotDirectory
+	}
+	inside, err := isInside(toPath, fromPath)
+	if err != nil {
+		return fmt.Errorf("get absolute path: %w", err)
+	}
+	if inside {
+		return ErrDestinationInsideSource
+	}
+
+	w := &dirWalker{o: o}
+	if err := w.walk(fromPath, toPath, ".", info); err != nil {
+		return err
+	}
+
+	o.progress.Start(w.total)
+	defer o.progress.Finish()
+	o.progressStarted = true
+
+	for _, e := range w.entries {
+		if err := copyEntry(e, o); err != nil {
+			return err
+		}
+	}
+
+	// directories metadata is set after their content is written, because it changes their times
+	for i := len(w.entries) - 1; i >= 0; i-- {
+		e := w.entries[i]
+		if !e.info.IsDir() {
+			continue
+		}
+		if err := preserveMetadata(e.src, e.dest, e.info, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyEntry(e dirEntry, o *options) error {
+	switch {
+	case e.info.IsDir():
+		if err := os.Mkdir(e.dest, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		return nil
+	case e.link != "":
+		if err := os.Remove(e.dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("replace symbolic link: %w", err)
+		}
+		if err := os.Symlink(e.link, e.dest); err != nil {
+			return fmt.Errorf("create symbolic link: %w", err)
+		}
+		if o.preserveOwner {
+			return chown(e.dest, e.info)
+		}
+		return nil
+	default:
+		if err := copyPath(e.src, e.dest, o); err != nil {
+			return fmt.Errorf("%s: %w", e.src, err)
+		}
+		return preserveMetadata(e.src, e.dest, e.info, o)
+	}
+}
+
+// copyPath copies the whole file, unlike Copy it does not treat StdinPath as the standard input.
+func copyPath(fromPath, toPath string, o *options) (err error) {
+	srcFile, err := os.Open(fromPath)
+	if err != nil {
+		return fmt.Errorf("open source file: %w", err)
+	}
+	defer func() {
+		if closeErr := srcFile.Close(); closeErr != nil {
+			err = errorJoin(err, fmt.Errorf("close source file: %w", closeErr))
+		}
+	}()
+
+	return copyFile(srcFile, toPath, 0, 0, o)
+}
+
+// dirWalker collects the entries to copy.
+type dirWalker struct {
+	o       *options
+	entries []dirEntry
+	// total is the size of all files.
+	total int64
+	// ancestors are the directories being walked, they detect loops of followed links.
+	ancestors []os.FileInfo
+}
+
+func (w *dirWalker) walk(src, dest, rel string, info os.FileInfo) error {
+	for _, ancestor := range w.ancestors {
+		if os.SameFile(ancestor, info) {
+			return fmt.Errorf("%s: %w", src, ErrSymlinkLoop)
+		}
+	}
+	w.ancestors = append(w.ancestors, info)
+	defer func() {
+		w.ancestors = w.ancestors[:len(w.ancestors)-1]
+	}()
+
+	w.entries = append(w.entries, dirEntry{src: src, dest: dest, info: info})
+
+	children, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("read directory: %w", err)
+	}
+
+	for _, child := range children {
+		e := dirEntry{
+			src:  filepath.Join(src, child.Name()),
+			dest: filepath.Join(dest, child.Name()),
+		}
+		childRel := filepath.Join(rel, child.Name())
+		if matchAny(w.o.exclude, childRel) {
+			continue
+		}
+
+		if e.info, err = os.Lstat(e.src); err != nil {
+			return fmt.Errorf("get file info: %w", err)
+		}
+		if e.info.Mode()&os.ModeSymlink != 0 {
+			if w.o.symlinks == SymlinksFollow {
+				if e.info, err = os.Stat(e.src); err != nil {
+					return fmt.Errorf("follow symbolic link: %w", err)
+				}
+			} else if e.link, err = os.Readlink(e.src); err != nil {
+				return fmt.Errorf("read symbolic link: %w", err)
+			}
+		}
+
+		switch {
+		case e.info.IsDir():
+			if err := w.walk(e.src, e.dest, childRel, e.info); err != nil {
+				return err
+			}
+		case len(w.o.include) > 0 && !matchAny(w.o.include, childRel):
+		case e.link != "":
+			w.entries = append(w.entries, e)
+		case e.info.Mode().IsRegular():
+			w.entries = append(w.entries, e)
+			w.total += e.info.Size()
+		default:
+			return fmt.Errorf("%s: %w", e.src, ErrUnsupportedFile)
+		}
+	}
+
+	return nil
+}
+
+// matchAny reports whether the base name or the relative path matches any of the patterns.
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInside reports whether the path is the directory or is inside it.
+func isInside(path, dir string) (bool, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+
+	return absPath == absDir || strings.HasPrefix(absPath, absDir+string(filepath.Separator)), nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createTree creates a directory tree with files, a directory, and symbolic links to them.
+func createTree(t *testing.T, root string) {
+	t.Helper()
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub", "deep"), 0o755))
+	for path, content := range map[string]string{
+		"a.txt":              "aaa",
+		"sub/b.log":          "bbbb",
+		"sub/deep/c.txt":     "ccccc",
+		"sub/deep/empty.txt": "",
+	} {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	require.NoError(t, os.Chmod(filepath.Join(root, "sub", "b.log"), 0o600))
+	require.NoError(t, os.Chmod(filepath.Join(root, "sub", "deep"), 0o750))
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(root, "link")))
+	require.NoError(t, os.Symlink("sub", filepath.Join(root, "dirlink")))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "sub", "deep"), mtime, mtime))
+}
+
+func TestCopyDir(t *testing.T) {
+	testDir := t.TempDir()
+	srcDir := filepath.Join(testDir, "src")
+	createTree(t, srcDir)
+
+	// files returns the relative paths of regular files and directories in the tree
+	files := func(t *testing.T, root string) []string {
+		t.Helper()
+		var paths []string
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			require.NoError(t, err)
+			rel, err := filepath.Rel(root, path)
+			require.NoError(t, err)
+			if rel != "." && d.Type()&os.ModeSymlink == 0 {
+				paths = append(paths, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return paths
+	}
+
+	t.Run("tree with metadata", func(t *testing.T) {
+		destDir := filepath.Join(testDir, "copy")
+		require.NoError(t, CopyDir(srcDir, destDir))
+		require.Equal(t, files(t, srcDir), files(t, destDir))
+
+		for _, rel := range files(t, srcDir) {
+			srcInfo, err := os.Stat(filepath.Join(srcDir, rel))
+			require.NoError(t, err)
+			destInfo, err := os.Stat(filepath.Join(destDir, rel))
+			require.NoError(t, err)
+
+			require.Equal(t, srcInfo.Mode(), destInfo.Mode(), rel)
+			require.Equal(t, srcInfo.ModTime(), destInfo.ModTime(), rel)
+			if !srcInfo.IsDir() {
+				src, err := os.ReadFile(filepath.Join(srcDir, rel))
+				require.NoError(t, err)
+				dest, err := os.ReadFile(filepath.Join(destDir, rel))
+				require.NoError(t, err)
+				require.Equal(t, src, dest, rel)
+			}
+		}
+
+		for link, target := range map[string]string{"link": "a.txt", "dirlink": "sub"} {
+			actual, err := os.Readlink(filepath.Join(destDir, link))
+			require.NoError(t, err)
+			require.Equal(t, target, actual)
+		}
+
+		// copying again replaces the files and the links
+		require.NoError(t, CopyDir(srcDir, destDir))
+	})
+
+	t.Run("follow symbolic links", func(t *testing.T) {
+		destDir := filepath.Join(testDir, "follow")
+		require.NoError(t, CopyDir(srcDir, destDir, WithSymlinks(SymlinksFollow)))
+
+		content, err := os.ReadFile(filepath.Join(destDir, "link"))
+		require.NoError(t, err)
+		require.Equal(t, "aaa", string(content))
+
+		info, err := os.Lstat(filepath.Join(destDir, "dirlink", "deep"))
+		require.NoError(t, err)
+		require.True(t, info.IsDir())
+	})
+
+	t.Run("symbolic link loop", func(t *testing.T) {
+		loopDir := filepath.Join(testDir, "loop")
+		require.NoError(t, os.MkdirAll(filepath.Join(loopDir, "dir"), 0o755))
+		require.NoError(t, os.Symlink("..", filepath.Join(loopDir, "dir", "parent")))
+
+		err := CopyDir(loopDir, filepath.Join(testDir, "loop-copy"), WithSymlinks(SymlinksFollow))
+		require.ErrorIs(t, err, ErrSymlinkLoop)
+		require.NoError(t, CopyDir(loopDir, filepath.Join(testDir, "loop-copy")))
+	})
+
+	t.Run("include and exclude", func(t *testing.T) {
+		destDir := filepath.Join(testDir, "filtered")
+		require.NoError(t, CopyDir(srcDir, destDir, WithInclude("*.txt"), WithExclude("sub/deep/empty.txt", "*link")))
+		require.Equal(t, []string{"a.txt", "sub", "sub/deep", "sub/deep/c.txt"}, files(t, destDir))
+		require.NoFileExists(t, filepath.Join(destDir, "link"))
+
+		destDir = filepath.Join(testDir, "excluded")
+		require.NoError(t, CopyDir(srcDir, destDir, WithExclude("sub")))
+		require.Equal(t, []string{"a.txt"}, files(t, destDir))
+	})
+
+	t.Run("file named as stdin path", func(t *testing.T) {
+		dashDir := filepath.Join(testDir, "dash")
+		require.NoError(t, os.Mkdir(dashDir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dashDir, StdinPath), []byte("file"), 0o644))
+		setStdin(t, "stdin")
+
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dashDir))
+		t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+
+		// the relative path of the file is StdinPath itself
+		destDir := filepath.Join(testDir, "dash-copy")
+		require.NoError(t, CopyDir(".", destDir))
+		content, err := os.ReadFile(filepath.Join(destDir, StdinPath))
+		require.NoError(t, err)
+		require.Equal(t, "file", string(content))
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		require.ErrorIs(t, CopyDir(filepath.Join(srcDir, "a.txt"), filepath.Join(testDir, "out")), ErrNotDirectory)
+		require.ErrorIs(t, CopyDir(srcDir, filepath.Join(srcDir, "sub", "copy")), ErrDestinationInsideSource)
+		require.ErrorIs(t, CopyDir(srcDir, filepath.Join(testDir, "out"), WithExclude("[")), ErrInvalidOptions)
+	})
+}
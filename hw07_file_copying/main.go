@@ -2,25 +2,193 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
 	from, to      string
 	limit, offset int64
+	resume        bool
+	verify        string
+	checksumFile  bool
+	atomicWrite   bool
+	perm          string
+	sparse        bool
+	noZeroCopy    bool
+	parallel      int
+	bwLimit       int64
+	bwBurst       int64
+	progress      string
+
+	recursive      bool
+	followSymlinks bool
+	preserveOwner  bool
+	preserveXattrs bool
+	include        patternsFlag
+	exclude        patternsFlag
 )
 
+// sizeFlag is a number of bytes flag accepting units, see ParseSize.
+type sizeFlag struct {
+	value *int64
+}
+
+func (f sizeFlag) String() string {
+	if f.value == nil {
+		return "0"
+	}
+	return strconv.FormatInt(*f.value, 10)
+}
+
+func (f sizeFlag) Set(value string) error {
+	n, err := ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*f.value = n
+	return nil
+}
+
+// patternsFlag is a flag that may be set several times.
+type patternsFlag []string
+
+func (f *patternsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *patternsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func init() {
-	flag.StringVar(&from, "from", "", "file to read from")
+	flag.StringVar(&from, "from", "", "file to read from, \"-\" for stdin")
 	flag.StringVar(&to, "to", "", "file to write to")
-	flag.Int64Var(&limit, "limit", 0, "limit of bytes to copy")
-	flag.Int64Var(&offset, "offset", 0, "offset in input file")
+	flag.Var(sizeFlag{&limit}, "limit", "limit of bytes to copy, e.g. 1024, 10K, 1.5G")
+	flag.Var(sizeFlag{&offset}, "offset", "offset in input file, e.g. 1024, 10K, 1.5G")
+	flag.Var(sizeFlag{&bwLimit}, "bwlimit", "limit of bytes copied per second, e.g. 50M")
+	flag.Var(sizeFlag{&bwBurst}, "bwburst", "bytes copied at once with -bwlimit, a tenth of it by default")
+	flag.BoolVar(&resume, "resume", false, "continue an interrupted copy")
+	flag.StringVar(&verify, "verify", "", "verify the copy with hash algorithm: "+strings.Join(HashAlgorithms(), ", "))
+	flag.BoolVar(&atomicWrite, "atomic", false, "write to a temporary file and rename it to the destination")
+	flag.StringVar(&perm, "chmod", "", "octal permissions of the destination, e.g. 640")
+	flag.BoolVar(&sparse, "sparse", false, "leave holes in the destination instead of zero blocks")
+	flag.BoolVar(&noZeroCopy, "no-zero-copy", false, "always copy the data through the process memory")
+	flag.IntVar(&parallel, "parallel", 1, "number of goroutines copying file chunks")
+	flag.StringVar(&progress, "progress", "auto",
+		"progress reporting: bar, quiet, json (lines to stderr), auto (bar if stderr is a terminal)")
+	flag.BoolVar(&checksumFile, "checksum-file", false, "write sha256sum file next to the destination")
+
+	flag.BoolVar(&recursive, "r", false, "copy the directory tree")
+	flag.BoolVar(&followSymlinks, "L", false, "copy files symbolic links point to instead of links")
+	flag.BoolVar(&preserveOwner, "preserve-owner", false, "preserve the owner and the group of files")
+	flag.BoolVar(&preserveXattrs, "preserve-xattrs", false, "preserve extended attributes of files")
+	flag.Var(&include, "include", "copy only files matching the glob pattern, may be repeated")
+	flag.Var(&exclude, "exclude", "skip files and directories matching the glob pattern, may be repeated")
 }
 
 func main() {
 	flag.Parse()
-	err := Copy(from, to, offset, limit)
+
+	opts, err := flagOptions()
+	if err != nil {
+		log.Fatalf("invalid flags: %s", err.Error())
+	}
+
+	if recursive {
+		if offset != 0 || limit != 0 {
+			log.Fatalf("invalid flags: offset and limit are not supported with -r")
+		}
+		err = CopyDir(from, to, opts...)
+	} else {
+		err = Copy(from, to, offset, limit, opts...)
+	}
 	if err != nil {
 		log.Fatalf("copy: %s", err.Error())
 	}
 }
+
+// flagOptions converts parsed flags to Copy options.
+func flagOptions() ([]Option, error) {
+	p, err := flagProgress()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []Option{WithProgress(p)}
+	if resume {
+		opts = append(opts, WithResume())
+	}
+	if verify != "" {
+		opts = append(opts, WithVerify(verify))
+	}
+	if checksumFile {
+		opts = append(opts, WithChecksumFile())
+	}
+	if atomicWrite {
+		opts = append(opts, WithAtomic())
+	}
+	if sparse {
+		opts = append(opts, WithSparse())
+	}
+	if noZeroCopy {
+		opts = append(opts, WithoutZeroCopy())
+	}
+	if parallel > 1 {
+		opts = append(opts, WithParallel(parallel))
+	}
+	if bwLimit > 0 {
+		opts = append(opts, WithBandwidthLimit(bwLimit, bwBurst))
+	}
+	if perm != "" {
+		mode, err := strconv.ParseUint(perm, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("chmod %q: %w", perm, err)
+		}
+		opts = append(opts, WithPermissions(os.FileMode(mode)))
+	}
+
+	if followSymlinks {
+		opts = append(opts, WithSymlinks(SymlinksFollow))
+	}
+	if preserveOwner {
+		opts = append(opts, WithOwnership())
+	}
+	if preserveXattrs {
+		opts = append(opts, WithXattrs())
+	}
+	if len(include) > 0 {
+		opts = append(opts, WithInclude(include...))
+	}
+	if len(exclude) > 0 {
+		opts = append(opts, WithExclude(exclude...))
+	}
+
+	return opts, nil
+}
+
+// jsonProgressInterval is the interval of JSON progress lines.
+const jsonProgressInterval = time.Second
+
+func flagProgress() (Progress, error) {
+	switch progress {
+	case "auto":
+		if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return NewBarProgress(), nil
+		}
+		return NewQuietProgress(), nil
+	case "bar":
+		return NewBarProgress(), nil
+	case "quiet":
+		return NewQuietProgress(), nil
+	case "json":
+		return NewJSONProgress(os.Stderr, jsonProgressInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown progress %q", progress)
+	}
+}
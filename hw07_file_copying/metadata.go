@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// preserveMetadata sets the mode and the times of the source to the destination,
+// the ownership and extended attributes are set if enabled.
+func preserveMetadata(src, dest string, info os.FileInfo, o *options) error {
+	if o.preserveOwner {
+		if err := chown(dest, info); err != nil {
+			return err
+		}
+	}
+	if o.xattrs {
+		if err := copyXattrs(src, dest); err != nil {
+			return fmt.Errorf("copy extended attributes of %s: %w", src, err)
+		}
+	}
+
+	// the permissions set by WithPermissions are kept
+	if !info.Mode().IsRegular() || o.perm == 0 {
+		mode := info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+		if err := os.Chmod(dest, mode); err != nil {
+			return fmt.Errorf("set mode: %w", err)
+		}
+	}
+
+	if err := os.Chtimes(dest, accessTime(info), info.ModTime()); err != nil {
+		return fmt.Errorf("set times: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+func accessTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atim.Unix())
+	}
+
+	return info.ModTime()
+}
+
+// chown sets the owner and the group of the file info to the path, symbolic links are not followed.
+func chown(path string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("preserve ownership: %w", errors.ErrUnsupported)
+	}
+	if err := os.Lchown(path, int(st.Uid), int(st.Gid)); err != nil {
+		return fmt.Errorf("preserve ownership: %w", err)
+	}
+
+	return nil
+}
+
+func copyXattrs(src, dest string) error {
+	size, err := syscall.Listxattr(src, nil)
+	if errors.Is(err, syscall.ENOTSUP) {
+		return nil
+	}
+	if err != nil || size == 0 {
+		return err
+	}
+
+	names := make([]byte, size)
+	if size, err = syscall.Listxattr(src, names); err != nil {
+		return err
+	}
+
+	for _, name := range bytes.Split(names[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+
+		size, err := syscall.Getxattr(src, string(name), nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, size)
+		if size, err = syscall.Getxattr(src, string(name), value); err != nil {
+			return err
+		}
+		if err := syscall.Setxattr(dest, string(name), value[:size], 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreserveMetadata(t *testing.T) {
+	testDir := t.TempDir()
+	srcDir := filepath.Join(testDir, "src")
+	require.NoError(t, os.Mkdir(srcDir, 0o755))
+	srcPath := filepath.Join(srcDir, "file.txt")
+	require.NoError(t, os.WriteFile(srcPath, []byte("data"), 0o644))
+
+	t.Run("access time", func(t *testing.T) {
+		atime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, os.Chtimes(srcPath, atime, atime.Add(time.Hour)))
+
+		destDir := filepath.Join(testDir, "atime")
+		require.NoError(t, CopyDir(srcDir, destDir))
+
+		info, err := os.Stat(filepath.Join(destDir, "file.txt"))
+		require.NoError(t, err)
+		require.Equal(t, atime, accessTime(info).UTC())
+	})
+
+	t.Run("extended attributes", func(t *testing.T) {
+		err := syscall.Setxattr(srcPath, "user.origin", []byte("test"), 0)
+		if errors.Is(err, syscall.ENOTSUP) {
+			t.Skip("file system does not support extended attributes")
+		}
+		require.NoError(t, err)
+
+		destDir := filepath.Join(testDir, "xattrs")
+		require.NoError(t, CopyDir(srcDir, destDir, WithXattrs()))
+
+		value := make([]byte, 16)
+		n, err := syscall.Getxattr(filepath.Join(destDir, "file.txt"), "user.origin", value)
+		require.NoError(t, err)
+		require.Equal(t, "test", string(value[:n]))
+	})
+
+	t.Run("ownership", func(t *testing.T) {
+		if os.Getuid() != 0 {
+			t.Skip("changing ownership requires root")
+		}
+		require.NoError(t, os.Lchown(srcPath, 1234, 4321))
+
+		destDir := filepath.Join(testDir, "owner")
+		require.NoError(t, CopyDir(srcDir, destDir, WithOwnership()))
+
+		info, err := os.Stat(filepath.Join(destDir, "file.txt"))
+		require.NoError(t, err)
+		st := info.Sys().(*syscall.Stat_t)
+		require.Equal(t, uint32(1234), st.Uid)
+		require.Equal(t, uint32(4321), st.Gid)
+	})
+}
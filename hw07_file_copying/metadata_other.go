@@ -0,0 +1,22 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+func accessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+func chown(_ string, _ os.FileInfo) error {
+	return fmt.Errorf("preserve ownership: %w", errors.ErrUnsupported)
+}
+
+func copyXattrs(_, _ string) error {
+	return errors.ErrUnsupported
+}
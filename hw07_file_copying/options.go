@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidOptions = errors.New("invalid options")
+
+// Option configures Copy and CopyDir.
+type Option func(*options)
+
+type options struct {
+	resume       bool
+	verify       string
+	checksumFile bool
+	atomic       bool
+	perm         os.FileMode
+	sparse       bool
+	noZeroCopy   bool
+	parallel     int
+	bwLimit      int64
+	bwBurst      int64
+	clock        Clock
+	// limiter is shared by all copied files, it is nil if the bandwidth is not limited.
+	limiter *tokenBucket
+
+	symlinks      SymlinkPolicy
+	preserveOwner bool
+	xattrs        bool
+	include       []string
+	exclude       []string
+
+	progress Progress
+	// progressStarted is set if the progress is shared by all copied files, otherwise it is started for every file.
+	progressStarted bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{clock: realClock{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.progress == nil {
+		o.progress = NewQuietProgress()
+	}
+
+	if o.bwLimit > 0 {
+		burst := o.bwBurst
+		if burst <= 0 {
+			burst = max(o.bwLimit/10, 1)
+		}
+		o.limiter = newTokenBucket(o.clock, o.bwLimit, burst)
+	}
+
+	return o
+}
+
+func (o *options) validate() error {
+	if o.atomic && o.resume {
+		return fmt.Errorf("%w: atomic and resume modes are mutually exclusive", ErrInvalidOptions)
+	}
+	if o.resume && o.parallel > 1 {
+		return fmt.Errorf("%w: resume and parallel modes are mutually exclusive", ErrInvalidOptions)
+	}
+	for _, pattern := range append(o.include, o.exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: pattern %q: %w", ErrInvalidOptions, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// WithResume continues an interrupted copy: the existing destination prefix is verified
+// and copying continues after it. Progress is saved to a state file next to the destination.
+func WithResume() Option {
+	return func(o *options) {
+		o.resume = true
+	}
+}
+
+// WithVerify re-reads the destination after the copy and compares its checksum with the copied data one.
+// The algorithm is one of HashAlgorithms.
+func WithVerify(algorithm string) Option {
+	return func(o *options) {
+		o.verify = algorithm
+	}
+}
+
+// WithChecksumFile writes the SHA-256 of the copied data next to the destination in the sha256sum format.
+func WithChecksumFile() Option {
+	return func(o *options) {
+		o.checksumFile = true
+	}
+}
+
+// WithAtomic writes the data to a temporary file in the destination directory
+// that replaces the destination only after the copy is completed and synced.
+// Permissions of the existing destination are preserved, a new destination gets the source permissions.
+func WithAtomic() Option {
+	return func(o *options) {
+		o.atomic = true
+	}
+}
+
+// WithPermissions sets permissions of the destination.
+func WithPermissions(perm os.FileMode) Option {
+	return func(o *options) {
+		o.perm = perm.Perm()
+	}
+}
+
+// WithSparse skips zero blocks of the copied data leaving holes in the destination.
+// Holes of the source file are detected without reading them where it is supported.
+func WithSparse() Option {
+	return func(o *options) {
+		o.sparse = true
+	}
+}
+
+// WithoutZeroCopy disables copying files by the kernel, the data is always copied through the process memory.
+func WithoutZeroCopy() Option {
+	return func(o *options) {
+		o.noZeroCopy = true
+	}
+}
+
+// WithParallel copies regular files in chunks by n goroutines, it is faster on storages
+// handling concurrent requests well, e.g. NVMe drives.
+func WithParallel(n int) Option {
+	return func(o *options) {
+		o.parallel = n
+	}
+}
+
+// WithBandwidthLimit limits the copy rate to perSecond bytes per second. Burst is the number of bytes
+// that may be read at once after a pause, it is a tenth of perSecond if it is not positive.
+func WithBandwidthLimit(perSecond, burst int64) Option {
+	return func(o *options) {
+		o.bwLimit = perSecond
+		o.bwBurst = burst
+	}
+}
+
+// WithClock replaces the system clock used for bandwidth limiting.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithProgress sets the progress reporting, nothing is reported by default.
+func WithProgress(p Progress) Option {
+	return func(o *options) {
+		o.progress = p
+	}
+}
+
+// WithSymlinks sets how CopyDir copies symbolic links, SymlinksCopy by default.
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(o *options) {
+		o.symlinks = policy
+	}
+}
+
+// WithOwnership makes CopyDir preserve the owner and the group of copied files, it usually requires root.
+func WithOwnership() Option {
+	return func(o *options) {
+		o.preserveOwner = true
+	}
+}
+
+// WithXattrs makes CopyDir preserve extended attributes of copied files and directories.
+func WithXattrs() Option {
+	return func(o *options) {
+		o.xattrs = true
+	}
+}
+
+// WithInclude makes CopyDir copy only files matching any of the patterns.
+// Patterns have filepath.Match syntax and match the base name or the path relative to the source directory.
+func WithInclude(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExclude makes CopyDir skip files and directories matching any of the patterns, see WithInclude.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
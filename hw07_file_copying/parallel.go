@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// parallelChunkSize is the size of file ranges copied by parallel workers.
+	parallelChunkSize int64 = 8 << 20
+	// parallelBufferSize is the buffer size of a parallel worker.
+	parallelBufferSize int64 = 1 << 20
+)
+
+// chunkCopier copies ranges of src from off to dest.
+type chunkCopier struct {
+	dest *os.File
+	src  *os.File
+	off  int64
+	// sparse enables holes detection in chunks.
+	sparse bool
+	// limiter limits the read rate of all workers, it may be nil.
+	limiter *tokenBucket
+	// progress is called after every write.
+	progress func(int64)
+}
+
+// run copies n bytes in chunks by the workers, the first error stops all the workers.
+func (c *chunkCopier) run(n int64, workers int) error {
+	// the destination has its final size, so the chunks can be written in any order
+	if err := c.dest.Truncate(n); err != nil {
+		return fmt.Errorf("truncate destination file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	chunks := (n + parallelChunkSize - 1) / parallelChunkSize
+	var next atomic.Int64
+	wg := &sync.WaitGroup{}
+	for range min(int64(workers), chunks) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			buf := make([]byte, min(parallelBufferSize, parallelChunkSize))
+			for ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if i >= chunks {
+					return
+				}
+
+				start := i * parallelChunkSize
+				end := min(start+parallelChunkSize, n)
+				if err := c.copyChunk(ctx, start, end, buf); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+
+	return nil
+}
+
+// copyChunk copies the destination range [start, end) from src at off+start.
+func (c *chunkCopier) copyChunk(ctx context.Context, start, end int64, buf []byte) error {
+	r := c.limiter.reader(newSectionReader(c.src, c.off+start, c.off+end, c.sparse))
+	var w io.Writer = io.NewOffsetWriter(c.dest, start)
+	if c.sparse {
+		w = newSparseWriter(c.dest, start)
+	}
+
+	copied := int64(0)
+	for ctx.Err() == nil {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			copied += int64(n)
+			c.progress(int64(n))
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() == nil && copied < end-start {
+		return fmt.Errorf("source file is shorter than expected: %w", io.ErrUnexpectedEOF)
+	}
+
+	return nil
+}
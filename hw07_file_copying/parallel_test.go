@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelCopy(t *testing.T) {
+	prevChunk, prevBuffer := parallelChunkSize, parallelBufferSize
+	parallelChunkSize, parallelBufferSize = 1000, 300
+	t.Cleanup(func() {
+		parallelChunkSize, parallelBufferSize = prevChunk, prevBuffer
+	})
+
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.bin")
+	input := make([]byte, 100500)
+	rand.New(rand.NewSource(1)).Read(input)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+	outPath := filepath.Join(testDir, "out.bin")
+
+	tests := []struct {
+		name   string
+		offset int64
+		limit  int64
+		opts   []Option
+	}{
+		{name: "entire file"},
+		{name: "offset and limit", offset: 1234, limit: 55555},
+		{name: "single chunk", offset: 10, limit: 500},
+		{name: "sparse", opts: []Option{WithSparse()}},
+		{name: "verify", offset: 7, opts: []Option{WithVerify("sha256")}},
+		{name: "atomic", opts: []Option{WithAtomic()}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(outPath, []byte("previous longer content"), 0o644))
+			require.NoError(t, Copy(inputPath, outPath, tc.offset, tc.limit, append(tc.opts, WithParallel(4))...))
+
+			end := int64(len(input))
+			if tc.limit > 0 {
+				end = tc.offset + tc.limit
+			}
+			content, err := os.ReadFile(outPath)
+			require.NoError(t, err)
+			require.Equal(t, input[tc.offset:end], content)
+		})
+	}
+
+	t.Run("progress", func(t *testing.T) {
+		src, err := os.Open(inputPath)
+		require.NoError(t, err)
+		defer src.Close()
+		dest, err := os.Create(outPath)
+		require.NoError(t, err)
+		defer dest.Close()
+
+		var copied atomic.Int64
+		require.NoError(t, (&chunkCopier{
+			dest:     dest,
+			src:      src,
+			off:      100,
+			progress: func(n int64) { copied.Add(n) },
+		}).run(50000, 8))
+		require.Equal(t, int64(50000), copied.Load())
+	})
+
+	t.Run("error stops workers", func(t *testing.T) {
+		src, err := os.Open(inputPath)
+		require.NoError(t, err)
+		defer src.Close()
+		dest, err := os.Create(outPath)
+		require.NoError(t, err)
+		defer dest.Close()
+
+		// the source is shorter than the copied range
+		c := &chunkCopier{dest: dest, src: src, progress: func(int64) {}}
+		err = c.run(int64(len(input))+5000, 4)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		require.ErrorIs(t, Copy(inputPath, outPath, 0, 0, WithParallel(2), WithResume()), ErrInvalidOptions)
+	})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+// Progress reports the progress of a copy, an instance is used for a single Copy or CopyDir call.
+type Progress interface {
+	// Start is called before the copy, total is the number of bytes to copy or zero if it is unknown.
+	Start(total int64)
+	// Add is called after n bytes are copied, it may be called concurrently in parallel mode.
+	Add(n int64)
+	// Finish is called after the copy.
+	Finish()
+}
+
+// streamBarTemplate is used when the size of the copied data is unknown.
+const streamBarTemplate pb.ProgressBarTemplate = `{{counters . }} {{cycle . "[<->    ]" "[ <->   ]" "[  <->  ]" ` +
+	`"[   <-> ]" "[    <->]"}} {{speed . }} {{etime . }}`
+
+type barProgress struct {
+	bar *pb.ProgressBar
+}
+
+// NewBarProgress returns the progress drawing a terminal progress bar, it is indeterminate if the total is unknown.
+func NewBarProgress() Progress {
+	return &barProgress{}
+}
+
+func (p *barProgress) Start(total int64) {
+	if total <= 0 {
+		p.bar = streamBarTemplate.Start64(0).Set(pb.Bytes, true)
+		return
+	}
+
+	p.bar = pb.Full.Start64(total).Set(pb.Bytes, true)
+}
+
+func (p *barProgress) Add(n int64) {
+	p.bar.Add64(n)
+}
+
+func (p *barProgress) Finish() {
+	p.bar.Finish()
+}
+
+type quietProgress struct{}
+
+// NewQuietProgress returns the progress reporting nothing.
+func NewQuietProgress() Progress {
+	return quietProgress{}
+}
+
+func (quietProgress) Start(int64) {}
+
+func (quietProgress) Add(int64) {}
+
+func (quietProgress) Finish() {}
+
+type callbackProgress struct {
+	fn     func(copied, total int64)
+	total  int64
+	copied atomic.Int64
+}
+
+// NewCallbackProgress returns the progress calling fn with the number of copied bytes after every write,
+// total is zero if it is unknown. Fn may be called concurrently in parallel mode.
+func NewCallbackProgress(fn func(copied, total int64)) Progress {
+	return &callbackProgress{fn: fn}
+}
+
+func (p *callbackProgress) Start(total int64) {
+	p.total = total
+}
+
+func (p *callbackProgress) Add(n int64) {
+	p.fn(p.copied.Add(n), p.total)
+}
+
+func (p *callbackProgress) Finish() {}
+
+// ProgressEvent is a line written by the JSON progress.
+type ProgressEvent struct {
+	Bytes int64 `json:"bytes"`
+	// Total is omitted if it is unknown.
+	Total int64 `json:"total,omitempty"`
+	// Rate is the average number of bytes copied per second.
+	Rate float64 `json:"rate"`
+	// ETA is the estimated number of seconds to finish, it is omitted if the total is unknown.
+	ETA  *float64 `json:"eta,omitempty"`
+	Done bool     `json:"done,omitempty"`
+}
+
+type jsonProgress struct {
+	w        io.Writer
+	interval time.Duration
+	total    int64
+	start    time.Time
+	copied   atomic.Int64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// defaultJSONInterval replaces a non-positive interval of NewJSONProgress.
+const defaultJSONInterval = time.Second
+
+// NewJSONProgress returns the progress writing a ProgressEvent JSON line to w every interval
+// and after the copy is finished. A non-positive interval is replaced by a second.
+func NewJSONProgress(w io.Writer, interval time.Duration) Progress {
+	if interval <= 0 {
+		interval = defaultJSONInterval
+	}
+
+	return &jsonProgress{w: w, interval: interval}
+}
+
+func (p *jsonProgress) Start(total int64) {
+	p.total = total
+	p.start = time.Now()
+	p.stop = make(chan struct{})
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.write(false)
+			}
+		}
+	}()
+}
+
+func (p *jsonProgress) Add(n int64) {
+	p.copied.Add(n)
+}
+
+func (p *jsonProgress) Finish() {
+	close(p.stop)
+	p.wg.Wait()
+	p.write(true)
+}
+
+func (p *jsonProgress) write(done bool) {
+	event := ProgressEvent{Bytes: p.copied.Load(), Total: p.total, Done: done}
+	if elapsed := time.Since(p.start).Seconds(); elapsed > 0 {
+		event.Rate = float64(event.Bytes) / elapsed
+	}
+	if p.total > 0 && event.Rate > 0 {
+		eta := float64(max(p.total-event.Bytes, 0)) / event.Rate
+		event.ETA = &eta
+	}
+
+	// errors of progress reporting do not fail the copy
+	data, _ := json.Marshal(event)
+	_, _ = p.w.Write(append(data, '\n'))
+}
+
+// progressReader reports the read bytes to the progress.
+type progressReader struct {
+	r        io.Reader
+	progress Progress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.progress.Add(int64(n))
+	}
+
+	return n, err
+}
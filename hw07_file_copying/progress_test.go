@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	input := bytes.Repeat([]byte("0123456789"), 10000)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+	outPath := filepath.Join(testDir, "out.txt")
+
+	t.Run("callback", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {WithoutZeroCopy()}, {WithParallel(4)}} {
+			mu := &sync.Mutex{}
+			var maxCopied, lastTotal int64
+			p := NewCallbackProgress(func(copied, total int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				maxCopied = max(maxCopied, copied)
+				lastTotal = total
+			})
+
+			require.NoError(t, Copy(inputPath, outPath, 100, 0, append(opts, WithProgress(p))...))
+			require.Equal(t, int64(len(input)-100), maxCopied)
+			require.Equal(t, int64(len(input)-100), lastTotal)
+		}
+	})
+
+	t.Run("quiet", func(t *testing.T) {
+		require.NoError(t, Copy(inputPath, outPath, 0, 0, WithProgress(NewQuietProgress())))
+		// library callers get no terminal output by default
+		require.Equal(t, NewQuietProgress(), newOptions(nil).progress)
+	})
+
+	t.Run("directory total", func(t *testing.T) {
+		srcDir := filepath.Join(testDir, "src")
+		createTree(t, srcDir)
+
+		var lastCopied, lastTotal int64
+		p := NewCallbackProgress(func(copied, total int64) {
+			lastCopied, lastTotal = copied, total
+		})
+		require.NoError(t, CopyDir(srcDir, filepath.Join(testDir, "dest"), WithProgress(p)))
+		require.Equal(t, int64(12), lastCopied)
+		require.Equal(t, int64(12), lastTotal)
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		p := NewJSONProgress(buf, 5*time.Millisecond)
+		p.Start(100)
+		p.Add(40)
+		time.Sleep(30 * time.Millisecond)
+		p.Add(60)
+		p.Finish()
+
+		var events []ProgressEvent
+		scanner := bufio.NewScanner(buf)
+		for scanner.Scan() {
+			var event ProgressEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+			events = append(events, event)
+		}
+		require.GreaterOrEqual(t, len(events), 2)
+
+		periodic := events[0]
+		require.Equal(t, int64(40), periodic.Bytes)
+		require.Equal(t, int64(100), periodic.Total)
+		require.Positive(t, periodic.Rate)
+		require.NotNil(t, periodic.ETA)
+		require.Positive(t, *periodic.ETA)
+		require.False(t, periodic.Done)
+
+		last := events[len(events)-1]
+		require.Equal(t, int64(100), last.Bytes)
+		require.True(t, last.Done)
+		require.Zero(t, *last.ETA)
+	})
+
+	t.Run("json lines with non-positive interval", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			buf := &bytes.Buffer{}
+			require.NoError(t, Copy(inputPath, outPath, 0, 0, WithProgress(NewJSONProgress(buf, interval))))
+
+			var event ProgressEvent
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+			require.True(t, event.Done)
+			require.Equal(t, int64(len(input)), event.Bytes)
+		}
+	})
+
+	t.Run("json lines of unknown total", func(t *testing.T) {
+		setStdin(t, "123456")
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, Copy(StdinPath, outPath, 0, 0, WithProgress(NewJSONProgress(buf, time.Hour))))
+
+		var event ProgressEvent
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+		require.Equal(t, ProgressEvent{Bytes: 6, Rate: event.Rate, Done: true}, event)
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Clock is a source of time, it allows to control delays in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// tokenBucket limits the number of bytes per second, it may be shared by several readers.
+// Taken bytes may exceed the available tokens, then the taker waits until the debt is refilled.
+type tokenBucket struct {
+	clock Clock
+	rate  float64
+	burst int64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(clock Clock, perSecond, burst int64) *tokenBucket {
+	return &tokenBucket{
+		clock:  clock,
+		rate:   float64(perSecond),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+// take takes n tokens and returns the delay until the taken tokens are available.
+func (b *tokenBucket) take(n int64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(b.burst), b.tokens+elapsed.Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait takes n tokens and waits until they are available.
+func (b *tokenBucket) wait(n int64) {
+	if d := b.take(n); d > 0 {
+		<-b.clock.After(d)
+	}
+}
+
+// reader returns the reader limited by the bucket, it is r itself if the bucket is nil.
+func (b *tokenBucket) reader(r io.Reader) io.Reader {
+	if b == nil {
+		return r
+	}
+
+	return &limitedReader{r: r, bucket: b}
+}
+
+// limitedReader reads not more than the burst at once and waits for the read bytes to be available.
+type limitedReader struct {
+	r      io.Reader
+	bucket *tokenBucket
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.bucket.burst {
+		p = p[:r.bucket.burst]
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.bucket.wait(int64(n))
+	}
+
+	return n, err
+}
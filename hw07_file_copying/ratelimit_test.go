@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances its time by the waited durations instead of sleeping.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// Total returns the sum of waited durations.
+func (c *fakeClock) Total() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total time.Duration
+	for _, d := range c.delays {
+		total += d
+	}
+	return total
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	bucket := newTokenBucket(clock, 100, 10)
+
+	require.Zero(t, bucket.take(10))
+	require.Equal(t, 100*time.Millisecond, bucket.take(10))
+	require.Equal(t, 150*time.Millisecond, bucket.take(5))
+
+	// the debt is refilled first
+	clock.After(time.Second)
+	require.Zero(t, bucket.take(5))
+	// tokens are not accumulated over the burst
+	clock.After(time.Hour)
+	require.Zero(t, bucket.take(10))
+	require.Equal(t, 10*time.Millisecond, bucket.take(1))
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	input := bytes.Repeat([]byte("0123456789"), 100)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+	outPath := filepath.Join(testDir, "out.txt")
+
+	t.Run("sequential copy", func(t *testing.T) {
+		clock := newFakeClock()
+		require.NoError(t, Copy(inputPath, outPath, 0, 0, WithBandwidthLimit(100, 50), WithClock(clock)))
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, input, content)
+
+		// the burst is read at once, then every 50 bytes wait for half a second
+		require.Len(t, clock.delays, 19)
+		require.Equal(t, 9500*time.Millisecond, clock.Total())
+	})
+
+	t.Run("default burst", func(t *testing.T) {
+		clock := newFakeClock()
+		require.NoError(t, Copy(inputPath, outPath, 500, 0, WithBandwidthLimit(1000, 0), WithClock(clock)))
+		require.Len(t, clock.delays, 4)
+		require.Equal(t, 400*time.Millisecond, clock.Total())
+	})
+
+	t.Run("parallel copy shares the limit", func(t *testing.T) {
+		prevChunk := parallelChunkSize
+		parallelChunkSize = 100
+		t.Cleanup(func() {
+			parallelChunkSize = prevChunk
+		})
+
+		clock := newFakeClock()
+		require.NoError(t, Copy(inputPath, outPath, 0, 0,
+			WithBandwidthLimit(100, 50), WithClock(clock), WithParallel(4)))
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, input, content)
+		require.GreaterOrEqual(t, clock.Total(), 9500*time.Millisecond)
+	})
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StateSuffix is the suffix of the resume state file name added to the destination path.
+const StateSuffix = ".copystate"
+
+var ErrResumeUnsupported = errors.New("resume is not supported for streams")
+
+var (
+	// checkpointInterval is the number of bytes copied between state saves.
+	checkpointInterval int64 = 16 << 20
+	// tailWindow is the size of the copied data tail verified on resume.
+	tailWindow int64 = 64 << 10
+)
+
+// resumeState is the progress of the copy saved to the state file.
+type resumeState struct {
+	Source     string    `json:"source"`
+	SourceSize int64     `json:"sourceSize"`
+	ModTime    time.Time `json:"modTime"`
+	Offset     int64     `json:"offset"`
+	Limit      int64     `json:"limit"`
+	Copied     int64     `json:"copied"`
+	// TailCRC is the CRC-32 of the last tailWindow bytes of the copied data.
+	TailCRC uint32 `json:"tailCrc"`
+}
+
+func (s resumeState) sameCopy(other resumeState) bool {
+	return s.Source == other.Source && s.SourceSize == other.SourceSize && s.ModTime.Equal(other.ModTime) &&
+		s.Offset == other.Offset && s.Limit == other.Limit
+}
+
+// checkpointer writes to the destination and periodically saves the copy progress.
+type checkpointer struct {
+	path string
+	dest *os.File
+	// w writes to dest, it is flushed before the state is saved.
+	w       io.Writer
+	state   resumeState
+	unsaved int64
+}
+
+func newCheckpointer(src, dest *os.File, offset, limit int64) (*checkpointer, error) {
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("get file info: %w", err)
+	}
+	source, err := filepath.Abs(src.Name())
+	if err != nil {
+		return nil, fmt.Errorf("get source path: %w", err)
+	}
+
+	return &checkpointer{
+		path: dest.Name() + StateSuffix,
+		dest: dest,
+		w:    dest,
+		state: resumeState{
+			Source:     source,
+			SourceSize: srcInfo.Size(),
+			ModTime:    srcInfo.ModTime(),
+			Offset:     offset,
+			Limit:      limit,
+		},
+	}, nil
+}
+
+// prepare finds the verified prefix of the destination and positions the destination after it.
+// The copy starts over if the prefix does not match the source.
+func (c *checkpointer) prepare(src io.ReaderAt) (int64, error) {
+	destInfo, err := c.dest.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("get destination file info: %w", err)
+	}
+
+	copied := min(destInfo.Size(), c.state.Limit)
+	var want *uint32
+	if saved, ok := c.load(); ok && saved.Copied <= copied {
+		copied = saved.Copied
+		want = &saved.TailCRC
+	}
+
+	if copied > 0 {
+		ok, err := c.verify(src, copied, want)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			copied = 0
+		}
+	}
+
+	if err := c.dest.Truncate(copied); err != nil {
+		return 0, fmt.Errorf("truncate destination file: %w", err)
+	}
+	if _, err := c.dest.Seek(copied, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek destination file: %w", err)
+	}
+	c.state.Copied = copied
+
+	return copied, nil
+}
+
+// load reads the saved state, it is ignored if it is missing or belongs to another copy.
+func (c *checkpointer) load() (resumeState, bool) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return resumeState{}, false
+	}
+
+	var saved resumeState
+	if err := json.Unmarshal(data, &saved); err != nil || !saved.sameCopy(c.state) {
+		return resumeState{}, false
+	}
+
+	return saved, true
+}
+
+// verify compares the tails of the copied source data and the destination prefix.
+func (c *checkpointer) verify(src io.ReaderAt, copied int64, want *uint32) (bool, error) {
+	destCRC, err := tailChecksum(c.dest, 0, copied)
+	if err != nil {
+		return false, fmt.Errorf("verify destination file: %w", err)
+	}
+	srcCRC, err := tailChecksum(src, c.state.Offset, copied)
+	if err != nil {
+		return false, fmt.Errorf("verify source file: %w", err)
+	}
+
+	return destCRC == srcCRC && (want == nil || *want == destCRC), nil
+}
+
+func (c *checkpointer) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.state.Copied += int64(n)
+	c.unsaved += int64(n)
+	if err == nil && c.unsaved >= checkpointInterval {
+		err = c.save()
+	}
+
+	return n, err
+}
+
+func (c *checkpointer) flush() error {
+	return flushWriter(c.w)
+}
+
+// finish removes the state file after a successful copy or saves the progress otherwise.
+func (c *checkpointer) finish(copyErr error) error {
+	if copyErr != nil {
+		if err := c.save(); err != nil {
+			return errorJoin(copyErr, err)
+		}
+		return copyErr
+	}
+
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove state file: %w", err)
+	}
+
+	return nil
+}
+
+// save flushes the destination and atomically replaces the state file.
+func (c *checkpointer) save() error {
+	if err := c.flush(); err != nil {
+		return fmt.Errorf("write destination file: %w", err)
+	}
+	if err := c.dest.Sync(); err != nil {
+		return fmt.Errorf("sync destination file: %w", err)
+	}
+
+	crc, err := tailChecksum(c.dest, 0, c.state.Copied)
+	if err != nil {
+		return fmt.Errorf("checksum destination file: %w", err)
+	}
+	c.state.TailCRC = crc
+
+	data, err := json.Marshal(c.state)
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+	if err := writeFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	c.unsaved = 0
+
+	return nil
+}
+
+// tailChecksum returns the CRC-32 of up to tailWindow bytes of r before base+end.
+func tailChecksum(r io.ReaderAt, base, end int64) (uint32, error) {
+	start := max(end-tailWindow, 0)
+
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, io.NewSectionReader(r, base+start, end-start)); err != nil {
+		return 0, err
+	}
+
+	return h.Sum32(), nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path.
+func writeFileAtomic(path string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResume(t *testing.T) {
+	prevInterval, prevWindow := checkpointInterval, tailWindow
+	checkpointInterval, tailWindow = 100, 16
+	t.Cleanup(func() {
+		checkpointInterval, tailWindow = prevInterval, prevWindow
+	})
+
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	input := bytes.Repeat([]byte("0123456789abcdef"), 64)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+
+	// prepare opens the destination with the given content and returns the resumed size
+	prepare := func(t *testing.T, content []byte, state *resumeState, offset, limit int64) int64 {
+		t.Helper()
+
+		outPath := filepath.Join(t.TempDir(), "out.txt")
+		require.NoError(t, os.WriteFile(outPath, content, 0o644))
+
+		src, err := os.Open(inputPath)
+		require.NoError(t, err)
+		defer src.Close()
+		dest, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE, 0o666)
+		require.NoError(t, err)
+		defer dest.Close()
+
+		cp, err := newCheckpointer(src, dest, offset, limit)
+		require.NoError(t, err)
+		if state != nil {
+			saved := cp.state
+			saved.Copied = state.Copied
+			saved.TailCRC = state.TailCRC
+			data, err := json.Marshal(saved)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(cp.path, data, 0o644))
+		}
+
+		resumed, err := cp.prepare(src)
+		require.NoError(t, err)
+
+		destInfo, err := dest.Stat()
+		require.NoError(t, err)
+		require.Equal(t, resumed, destInfo.Size())
+
+		return resumed
+	}
+
+	t.Run("verified prefix", func(t *testing.T) {
+		require.Equal(t, int64(300), prepare(t, input[:300], nil, 0, int64(len(input))))
+		require.Equal(t, int64(200), prepare(t, input[10:210], nil, 10, 500))
+	})
+
+	t.Run("prefix longer than limit", func(t *testing.T) {
+		require.Equal(t, int64(100), prepare(t, input[:300], nil, 0, 100))
+	})
+
+	t.Run("corrupted prefix", func(t *testing.T) {
+		content := append([]byte(nil), input[:300]...)
+		content[299] = 'x'
+		require.Zero(t, prepare(t, content, nil, 0, int64(len(input))))
+	})
+
+	t.Run("state file", func(t *testing.T) {
+		crc, err := tailChecksum(bytes.NewReader(input), 0, 200)
+		require.NoError(t, err)
+
+		// the data after the saved progress may be incomplete
+		content := append(append([]byte(nil), input[:200]...), "garbage"...)
+		require.Equal(t, int64(200), prepare(t, content, &resumeState{Copied: 200, TailCRC: crc}, 0, int64(len(input))))
+		require.Zero(t, prepare(t, content, &resumeState{Copied: 200, TailCRC: crc + 1}, 0, int64(len(input))))
+	})
+
+	t.Run("copy continues and removes state", func(t *testing.T) {
+		outPath := filepath.Join(testDir, "out.txt")
+		require.NoError(t, os.WriteFile(outPath, input[100:400], 0o644))
+
+		require.NoError(t, Copy(inputPath, outPath, 100, 800, WithResume()))
+
+		content, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, input[100:900], content)
+		require.NoFileExists(t, outPath+StateSuffix)
+	})
+
+	t.Run("checkpoints are saved", func(t *testing.T) {
+		outPath := filepath.Join(testDir, "checkpoint.txt")
+		dest, err := os.OpenFile(outPath, os.O_RDWR|os.O_CREATE, 0o666)
+		require.NoError(t, err)
+		defer dest.Close()
+
+		cp := &checkpointer{path: outPath + StateSuffix, dest: dest, w: dest}
+		_, err = cp.Write(input[:60])
+		require.NoError(t, err)
+		require.NoFileExists(t, cp.path)
+
+		_, err = cp.Write(input[60:150])
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(cp.path)
+		require.NoError(t, err)
+		var saved resumeState
+		require.NoError(t, json.Unmarshal(data, &saved))
+		require.Equal(t, int64(150), saved.Copied)
+
+		crc, err := tailChecksum(bytes.NewReader(input), 0, 150)
+		require.NoError(t, err)
+		require.Equal(t, crc, saved.TailCRC)
+	})
+
+	t.Run("streams are not supported", func(t *testing.T) {
+		setStdin(t, "123456")
+
+		err := Copy(StdinPath, filepath.Join(testDir, "stream.txt"), 0, 0, WithResume())
+		require.ErrorIs(t, err, ErrResumeUnsupported)
+	})
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSize = errors.New("invalid size")
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"T", 1 << 40},
+	{"P", 1 << 50},
+	{"", 1},
+}
+
+// ParseSize parses a number of bytes with an optional binary unit suffix: K, M, G, T or P,
+// followed by an optional "B" or "iB", e.g. "512", "50M", "1.5GiB", "10KB". Units are case-insensitive.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "IB"), "B")
+
+	for _, unit := range sizeUnits {
+		number, ok := strings.CutSuffix(value, unit.suffix)
+		if !ok {
+			continue
+		}
+
+		// integers are parsed exactly, float64 loses precision above 2^53
+		if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+			if n < 0 || n > math.MaxInt64/unit.multiplier {
+				break
+			}
+			return n * unit.multiplier, nil
+		}
+
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil || math.IsNaN(n) || n < 0 || n*float64(unit.multiplier) >= math.MaxInt64 {
+			break
+		}
+		return int64(n * float64(unit.multiplier)), nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+}
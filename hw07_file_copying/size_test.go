@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "0", expected: 0},
+		{input: "1000", expected: 1000},
+		{input: "512B", expected: 512},
+		{input: "10K", expected: 10 << 10},
+		{input: "10kb", expected: 10 << 10},
+		{input: "50M", expected: 50 << 20},
+		{input: "1.5GiB", expected: 3 << 29},
+		{input: " 2T ", expected: 2 << 40},
+		{input: "1P", expected: 1 << 50},
+		{input: "8191P", expected: 8191 << 50},
+		{input: "9223372036854775807", expected: math.MaxInt64},
+		{input: "9007199254740993", expected: 1<<53 + 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			n, err := ParseSize(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, n)
+		})
+	}
+
+	invalid := []string{
+		"", "B", "-1", "10X", "1.2.3M", "NaN", "9000P", "K10",
+		// values overflowing int64
+		"8192P", "8192.0P", "9223372036854775808", "8E18K",
+	}
+	for _, input := range invalid {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseSize(input)
+			require.ErrorIs(t, err, ErrInvalidSize)
+		})
+	}
+}
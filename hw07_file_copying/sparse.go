@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// sparseBlockSize is the size of zero blocks skipped in the destination.
+const sparseBlockSize = 4096
+
+var zeroBlock [sparseBlockSize]byte
+
+// holeReader reads the file section [pos, end), holes of the file are read as zeros without reading the file.
+type holeReader struct {
+	f   *os.File
+	pos int64
+	end int64
+	// [dataStart, dataEnd) is the current data range, the range before dataStart is a hole.
+	dataStart int64
+	dataEnd   int64
+}
+
+func newHoleReader(f *os.File, start, end int64) *holeReader {
+	return &holeReader{f: f, pos: start, end: end, dataStart: start, dataEnd: start}
+}
+
+func (r *holeReader) Read(p []byte) (int, error) {
+	if r.pos >= r.end {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), r.end-r.pos)]
+
+	if r.pos >= r.dataEnd {
+		start, end, err := nextData(r.f, r.pos)
+		if err != nil {
+			return 0, err
+		}
+		r.dataStart, r.dataEnd = start, end
+	}
+
+	if r.pos < r.dataStart {
+		n := min(int64(len(p)), r.dataStart-r.pos)
+		clear(p[:n])
+		r.pos += n
+		return int(n), nil
+	}
+
+	n, err := r.f.ReadAt(p[:min(int64(len(p)), r.dataEnd-r.pos)], r.pos)
+	r.pos += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	return n, err
+}
+
+// newSectionReader returns a reader of the file section [start, end), holes are detected in sparse mode.
+func newSectionReader(f *os.File, start, end int64, sparse bool) io.Reader {
+	if sparse {
+		return newHoleReader(f, start, end)
+	}
+
+	return io.NewSectionReader(f, start, end-start)
+}
+
+// sparseWriter writes to the file from pos, blocks of zeros are skipped leaving holes in the file.
+type sparseWriter struct {
+	f    *os.File
+	pos  int64
+	size int64
+}
+
+func newSparseWriter(f *os.File, pos int64) *sparseWriter {
+	return &sparseWriter{f: f, pos: pos, size: pos}
+}
+
+func (w *sparseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// data blocks before the next zero block are written at once
+		dataLen := 0
+		for dataLen < len(p) {
+			blockLen := min(len(p)-dataLen, sparseBlockSize-int((w.pos+int64(dataLen))%sparseBlockSize))
+			if bytes.Equal(p[dataLen:dataLen+blockLen], zeroBlock[:blockLen]) {
+				if dataLen == 0 {
+					w.pos += int64(blockLen)
+					written += blockLen
+					p = p[blockLen:]
+					continue
+				}
+				break
+			}
+			dataLen += blockLen
+		}
+		if dataLen == 0 {
+			continue
+		}
+
+		n, err := w.f.WriteAt(p[:dataLen], w.pos)
+		w.pos += int64(n)
+		w.size = max(w.size, w.pos)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[dataLen:]
+	}
+
+	return written, nil
+}
+
+// flush extends the file to the written size if it ends with a hole.
+func (w *sparseWriter) flush() error {
+	if w.pos <= w.size {
+		return nil
+	}
+	if err := w.f.Truncate(w.pos); err != nil {
+		return err
+	}
+	w.size = w.pos
+
+	return nil
+}
+
+// flushWriter flushes the writer if it buffers the data.
+func flushWriter(w io.Writer) error {
+	if f, ok := w.(interface{ flush() error }); ok {
+		return f.flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"os"
+	"syscall"
+)
+
+const (
+	seekData = 3
+	seekHole = 4
+)
+
+// nextData returns the data range of the file at or after pos using SEEK_DATA and SEEK_HOLE.
+// The rest of the file is a hole if there is no data after pos.
+// The whole file is data if the file system does not support holes detection.
+func nextData(f *os.File, pos int64) (int64, int64, error) {
+	start, err := f.Seek(pos, seekData)
+	switch {
+	case errors.Is(err, syscall.ENXIO):
+		return math.MaxInt64, math.MaxInt64, nil
+	case errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.EOPNOTSUPP):
+		return pos, math.MaxInt64, nil
+	case err != nil:
+		return 0, 0, err
+	}
+
+	end, err := f.Seek(start, seekHole)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
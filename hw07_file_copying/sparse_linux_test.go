@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseCopyAllocation(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.img")
+	createSparseFile(t, inputPath, 8<<20, map[int64]string{0: "header", 4 << 20: "middle"})
+
+	// allocated returns the number of bytes allocated for the file
+	allocated := func(path string) int64 {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.Sys().(*syscall.Stat_t).Blocks * 512
+	}
+	if allocated(inputPath) >= 1<<20 {
+		t.Skip("file system does not support holes")
+	}
+
+	outPath := filepath.Join(testDir, "out.img")
+	require.NoError(t, Copy(inputPath, outPath, 0, 0, WithSparse()))
+	require.Less(t, allocated(outPath), int64(1<<20))
+
+	require.NoError(t, Copy(inputPath, outPath, 0, 0))
+	require.GreaterOrEqual(t, allocated(outPath), int64(8<<20))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createSparseFile creates a file of the size with the data written at the offsets, the rest is holes.
+func createSparseFile(t *testing.T, path string, size int64, data map[int64]string) []byte {
+	t.Helper()
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, f.Truncate(size))
+
+	content := make([]byte, size)
+	for off, s := range data {
+		_, err := f.WriteAt([]byte(s), off)
+		require.NoError(t, err)
+		copy(content[off:], s)
+	}
+
+	return content
+}
+
+func TestSparseCopy(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.img")
+	content := createSparseFile(t, inputPath, 1<<20, map[int64]string{
+		0:             "header",
+		300 << 10:     "middle",
+		600<<10 + 100: "unaligned",
+	})
+	outPath := filepath.Join(testDir, "out.img")
+
+	tests := []struct {
+		name   string
+		offset int64
+		limit  int64
+		opts   []Option
+	}{
+		{name: "entire file"},
+		{name: "section", offset: 100, limit: 700 << 10},
+		{name: "trailing hole", offset: 4096},
+		{name: "verify", opts: []Option{WithVerify("crc32c")}},
+		{name: "atomic", opts: []Option{WithAtomic()}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, Copy(inputPath, outPath, tc.offset, tc.limit, append(tc.opts, WithSparse())...))
+
+			end := int64(len(content))
+			if tc.limit > 0 {
+				end = tc.offset + tc.limit
+			}
+			actual, err := os.ReadFile(outPath)
+			require.NoError(t, err)
+			require.Equal(t, content[tc.offset:end], actual)
+		})
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(outPath, content[:400<<10], 0o644))
+		require.NoError(t, Copy(inputPath, outPath, 0, 0, WithSparse(), WithResume()))
+
+		actual, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, content, actual)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		setStdin(t, string(content[:300<<10]))
+		require.NoError(t, Copy(StdinPath, outPath, 0, 0, WithSparse()))
+
+		actual, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		require.Equal(t, content[:300<<10], actual)
+	})
+}
+
+func TestSparseWriter(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	data := make([]byte, 3*sparseBlockSize)
+	copy(data[sparseBlockSize+10:], "data")
+
+	// writes are not aligned to blocks
+	w := newSparseWriter(f, 0)
+	for _, chunk := range [][]byte{data[:100], data[100 : 2*sparseBlockSize+1], data[2*sparseBlockSize+1:]} {
+		n, err := w.Write(chunk)
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+	require.NoError(t, w.flush())
+
+	actual, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, actual))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// zeroCopyChunk is the number of bytes copied between progress updates.
+const zeroCopyChunk = 8 << 20
+
+// zeroCopy copies n bytes of src from off to the current offset of dest with (*os.File).ReadFrom,
+// which lets the kernel copy the data with copy_file_range(2) or sendfile(2) where they are supported
+// and falls back to copying through the process memory otherwise. Progress is called after every chunk.
+func zeroCopy(dest, src *os.File, off, n int64, progress func(int64)) (written int64, err error) {
+	if _, err := src.Seek(off, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seek source file: %w", err)
+	}
+
+	for written < n {
+		m, err := io.CopyN(dest, src, min(n-written, zeroCopyChunk))
+		if m > 0 {
+			written += m
+			progress(m)
+		}
+		if errors.Is(err, io.EOF) {
+			// the source is shorter than expected
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroCopy(t *testing.T) {
+	testDir := t.TempDir()
+	inputPath := filepath.Join(testDir, "input.txt")
+	input := bytes.Repeat([]byte("0123456789"), 100000)
+	require.NoError(t, os.WriteFile(inputPath, input, 0o644))
+
+	tests := []struct {
+		name   string
+		offset int64
+		limit  int64
+	}{
+		{name: "entire file"},
+		{name: "offset", offset: 12345},
+		{name: "offset and limit", offset: 777, limit: 500000},
+		{name: "limit exceeds file size", offset: 999990, limit: 100},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fastPath := filepath.Join(testDir, "fast.txt")
+			slowPath := filepath.Join(testDir, "slow.txt")
+			require.NoError(t, Copy(inputPath, fastPath, tc.offset, tc.limit))
+			require.NoError(t, Copy(inputPath, slowPath, tc.offset, tc.limit, WithoutZeroCopy()))
+
+			fast, err := os.ReadFile(fastPath)
+			require.NoError(t, err)
+			slow, err := os.ReadFile(slowPath)
+			require.NoError(t, err)
+
+			end := int64(len(input))
+			if tc.limit > 0 {
+				end = min(end, tc.offset+tc.limit)
+			}
+			require.Equal(t, input[tc.offset:end], fast)
+			require.Equal(t, fast, slow)
+		})
+	}
+
+	t.Run("progress by chunks", func(t *testing.T) {
+		chunksPath := filepath.Join(testDir, "chunks.bin")
+		chunksInput := bytes.Repeat([]byte{1, 2, 3, 4}, (2*zeroCopyChunk+100)/4)
+		require.NoError(t, os.WriteFile(chunksPath, chunksInput, 0o644))
+
+		src, err := os.Open(chunksPath)
+		require.NoError(t, err)
+		defer src.Close()
+		dest, err := os.Create(filepath.Join(testDir, "chunks-out.bin"))
+		require.NoError(t, err)
+		defer dest.Close()
+
+		var chunks []int64
+		written, err := zeroCopy(dest, src, 4, int64(len(chunksInput)), func(n int64) { chunks = append(chunks, n) })
+		require.NoError(t, err)
+		require.Equal(t, int64(len(chunksInput)-4), written)
+		require.Equal(t, []int64{zeroCopyChunk, zeroCopyChunk, 96}, chunks)
+
+		content, err := os.ReadFile(dest.Name())
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(chunksInput[4:], content))
+	})
+}